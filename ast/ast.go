@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
 	"monkey/token"
 	"strings"
 )
@@ -71,6 +72,8 @@ type LetStatement struct {
 	//parts of the language produce value
 	Name     *Identifier
 	Property Expression
+	Indices  []Expression  // set for index assignments. eg. let h["key"] = 1; or let m[i][j] = 0;
+	Names    []*Identifier // set instead of Name when unpacking. eg. let (a, b) = pair;
 	Value    Expression
 }
 
@@ -84,11 +87,24 @@ func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(ls.TokenLiteral() + " ")
-	out.WriteString(ls.Name.String())
+	if ls.Names != nil {
+		names := []string{}
+		for _, name := range ls.Names {
+			names = append(names, name.String())
+		}
+		out.WriteString("(" + strings.Join(names, ", ") + ")")
+	} else {
+		out.WriteString(ls.Name.String())
+	}
 	if ls.Property != nil {
 		out.WriteString(".")
 		out.WriteString(ls.Property.String())
 	}
+	for _, index := range ls.Indices {
+		out.WriteString("[")
+		out.WriteString(index.String())
+		out.WriteString("]")
+	}
 
 	if ls.Value != nil {
 		out.WriteString(" = ")
@@ -147,6 +163,7 @@ func (es *ExpressionStatement) String() string {
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	Big   *big.Int // set instead of Value when the literal does not fit in an int64
 }
 
 //expressionNode interface implementation for Expression Interface
@@ -402,6 +419,37 @@ func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
 //String returns a string form of the node
 func (sl *StringLiteral) String() string { return sl.Token.Literal }
 
+//RegexLiteral node to hold regular expressions. eg. r/(\w+)@(\w+)/i
+type RegexLiteral struct {
+	Token   token.Token // the whole literal
+	Pattern string
+	Flags   string
+}
+
+//expressionNode implementation of the Expression interface
+func (rl *RegexLiteral) expressionNode() {}
+
+//TokenLiteral returns a literal string representation of the node
+func (rl *RegexLiteral) TokenLiteral() string { return rl.Token.Literal }
+
+//String returns a string form of the node
+func (rl *RegexLiteral) String() string { return rl.Token.Literal }
+
+//BytesLiteral node to hold byte strings. eg. b"\x89PNG"
+type BytesLiteral struct {
+	Token token.Token // the literal as written between the quotes
+	Value []byte
+}
+
+//expressionNode implementation of the Expression interface
+func (bl *BytesLiteral) expressionNode() {}
+
+//TokenLiteral returns a literal string representation of the node
+func (bl *BytesLiteral) TokenLiteral() string { return bl.Token.Literal }
+
+//String returns a string form of the node
+func (bl *BytesLiteral) String() string { return "b\"" + bl.Token.Literal + "\"" }
+
 //ArrayLiteral node to hold arrays
 type ArrayLiteral struct {
 	Token    token.Token // the '[' token
@@ -427,6 +475,59 @@ func (al *ArrayLiteral) String() string {
 	return out.String()
 }
 
+//SetLiteral node to hold sets. eg. {1, 2, 3}
+type SetLiteral struct {
+	Token    token.Token // the '{' token
+	Elements []Expression
+}
+
+//expressionNode implementation of the Expression interface
+func (sl *SetLiteral) expressionNode() {}
+
+//TokenLiteral returns a literal string representation of the node
+func (sl *SetLiteral) TokenLiteral() string { return sl.Token.Literal }
+
+//String returns a string form of the node
+func (sl *SetLiteral) String() string {
+	var out bytes.Buffer
+	elements := []string{}
+	for _, el := range sl.Elements {
+		elements = append(elements, el.String())
+	}
+	out.WriteString("{")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+//TupleLiteral node to hold tuples. eg. (1, "a") or (1,)
+type TupleLiteral struct {
+	Token    token.Token // the '(' token
+	Elements []Expression
+}
+
+//expressionNode implementation of the Expression interface
+func (tl *TupleLiteral) expressionNode() {}
+
+//TokenLiteral returns a literal string representation of the node
+func (tl *TupleLiteral) TokenLiteral() string { return tl.Token.Literal }
+
+//String returns a string form of the node
+func (tl *TupleLiteral) String() string {
+	var out bytes.Buffer
+	elements := []string{}
+	for _, el := range tl.Elements {
+		elements = append(elements, el.String())
+	}
+	out.WriteString("(")
+	out.WriteString(strings.Join(elements, ", "))
+	if len(elements) == 1 {
+		out.WriteString(",")
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
 //ArrayLiteral node to hold arrays
 type IndexExpression struct {
 	Token token.Token // the '[' token
@@ -451,10 +552,42 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+//SliceExpression node to hold slicing. eg. items[1:3], either bound may be left out
+type SliceExpression struct {
+	Token token.Token // the '[' token
+	Left  Expression
+	Start Expression
+	End   Expression
+}
+
+//expressionNode implementation of the Expression interface
+func (se *SliceExpression) expressionNode() {}
+
+//TokenLiteral returns a literal string representation of the node
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+
+//String returns a string form of the node
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	out.WriteString("])")
+	return out.String()
+}
+
 //HashLiteral node to hold arrays
 type HashLiteral struct {
-	Token token.Token // the '[' token
+	Token token.Token // the '{' token
 	Pairs map[Expression]Expression
+	Keys  []Expression // the keys of Pairs in source order
 }
 
 //expressionNode implementation of the Expression interface
@@ -467,8 +600,8 @@ func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, key := range hl.Keys {
+		pairs = append(pairs, key.String()+":"+hl.Pairs[key].String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
@@ -481,9 +614,10 @@ func (hl *HashLiteral) String() string {
 type ClassStatement struct {
 	Token token.Token // the token.CLASS token
 	// the name of the class
-	Name    *Identifier
-	Parents []*Identifier
-	Body    *BlockStatement
+	Name       *Identifier
+	Parents    []*Identifier
+	Interfaces []*Identifier // interfaces listed after the implements keyword
+	Body       *BlockStatement
 }
 
 //statementNode : implementer of Statement interface
@@ -506,13 +640,69 @@ func (Cs *ClassStatement) String() string {
 	}
 	out.WriteString("(")
 	out.WriteString(strings.Join(parents, ", "))
-	out.WriteString(") {")
+	out.WriteString(")")
+	if len(Cs.Interfaces) > 0 {
+		interfaces := []string{}
+		for _, i := range Cs.Interfaces {
+			interfaces = append(interfaces, i.String())
+		}
+		out.WriteString(" implements ")
+		out.WriteString(strings.Join(interfaces, ", "))
+	}
+	out.WriteString(" {")
 	out.WriteString(Cs.Body.String())
 	out.WriteString("}")
 	return out.String()
 
 }
 
+//InterfaceMethod : a method signature declared in an interface
+// eg. fn area(scale);
+type InterfaceMethod struct {
+	Token      token.Token // the token.FUNCTION token
+	Name       *Identifier
+	Parameters []*Identifier
+}
+
+//String : returns string representation of the method signature
+func (im *InterfaceMethod) String() string {
+	params := []string{}
+	for _, p := range im.Parameters {
+		params = append(params, p.String())
+	}
+	return im.Token.Literal + " " + im.Name.String() + "(" + strings.Join(params, ", ") + ");"
+}
+
+//InterfaceStatement : Node for interface declarations
+// eg. interface Shape { fn area(); fn scale(factor); }
+type InterfaceStatement struct {
+	Token   token.Token // the token.INTERFACE token
+	Name    *Identifier
+	Methods []*InterfaceMethod
+}
+
+//statementNode : implementer of Statement interface
+func (is *InterfaceStatement) statementNode() {}
+
+//expressionNode implementer
+func (is *InterfaceStatement) expressionNode() {}
+
+//TokenLiteral : a string representation of the token.INTERFACE token
+func (is *InterfaceStatement) TokenLiteral() string { return is.Token.Literal }
+
+//String : returns string representation of Node
+func (is *InterfaceStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(is.TokenLiteral() + " ")
+	out.WriteString(is.Name.String())
+	out.WriteString(" {")
+	for _, m := range is.Methods {
+		out.WriteString(m.String())
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
 //ImportStatement : statement Node to handle import statements
 // eg. return 5;
 type ImportStatement struct {
@@ -562,3 +752,114 @@ func (Ne *NullExpression) TokenLiteral() string { return Ne.Token.Literal }
 func (Ne *NullExpression) String() string {
 	return Ne.Token.Literal
 }
+
+//EnumStatement : Node for enum declarations
+// eg. enum Color { Red, Green, Blue }
+type EnumStatement struct {
+	Token   token.Token // the token.ENUM token
+	Name    *Identifier
+	Members []*Identifier
+}
+
+//statementNode : implementer of Statement interface
+func (es *EnumStatement) statementNode() {}
+
+//expressionNode implementer
+func (es *EnumStatement) expressionNode() {}
+
+//TokenLiteral : a string representation of the token.ENUM token
+func (es *EnumStatement) TokenLiteral() string { return es.Token.Literal }
+
+//String : returns string representation of Node
+func (es *EnumStatement) String() string {
+	members := []string{}
+	for _, m := range es.Members {
+		members = append(members, m.String())
+	}
+	return es.TokenLiteral() + " " + es.Name.String() + " {" + strings.Join(members, ", ") + "}"
+}
+
+//RecordStatement : Node for record declarations
+// eg. record Point(x, y)
+type RecordStatement struct {
+	Token  token.Token // the token.RECORD token
+	Name   *Identifier
+	Fields []*Identifier
+}
+
+//statementNode : implementer of Statement interface
+func (rs *RecordStatement) statementNode() {}
+
+//expressionNode implementer
+func (rs *RecordStatement) expressionNode() {}
+
+//TokenLiteral : a string representation of the token.RECORD token
+func (rs *RecordStatement) TokenLiteral() string { return rs.Token.Literal }
+
+//String : returns string representation of Node
+func (rs *RecordStatement) String() string {
+	fields := []string{}
+	for _, f := range rs.Fields {
+		fields = append(fields, f.String())
+	}
+	return rs.TokenLiteral() + " " + rs.Name.String() + "(" + strings.Join(fields, ", ") + ")"
+}
+
+//MatchArm : a single `pattern => body` arm of a match expression
+// a nil Pattern is the `_` wildcard
+type MatchArm struct {
+	Token   token.Token // the token.ARROW token
+	Pattern Expression
+	Body    *BlockStatement
+}
+
+//String : returns string representation of the arm
+func (ma *MatchArm) String() string {
+	pattern := "_"
+	if ma.Pattern != nil {
+		pattern = ma.Pattern.String()
+	}
+	return pattern + " => {" + ma.Body.String() + "}"
+}
+
+//MatchExpression : Node for match expressions
+// eg. match (color) { Color.Red => 1, _ => 2 }
+type MatchExpression struct {
+	Token   token.Token // the token.MATCH token
+	Subject Expression
+	Arms    []*MatchArm
+}
+
+//expressionNode interface implementation for Expression Interface
+func (me *MatchExpression) expressionNode() {}
+
+//TokenLiteral : a string representation of the token.MATCH token
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+
+//String : returns string representation of Node
+func (me *MatchExpression) String() string {
+	arms := []string{}
+	for _, a := range me.Arms {
+		arms = append(arms, a.String())
+	}
+	return "match(" + me.Subject.String() + ") {" + strings.Join(arms, ", ") + "}"
+}
+
+//DotExpression Node for attribute access on any expression
+// eg. player.speed, getPlayer().play, a.b.c
+type DotExpression struct {
+	Token token.Token // the '.' token
+	Left  Expression
+	Name  *Identifier
+}
+
+//expressionNode interface implementation for Expression Interface
+func (de *DotExpression) expressionNode() {}
+
+//TokenLiteral : a string representation of the '.' token
+func (de *DotExpression) TokenLiteral() string { return de.Token.Literal }
+
+//String : returns string representation of Node
+func (de *DotExpression) String() string {
+	return de.Left.String() + "." + de.Name.String()
+}
@@ -0,0 +1,479 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"sort"
+)
+
+//arrayBuiltins are available both as builtins taking the array as their first
+// argument and as methods on arrays. eg. filter(xs, f) or xs.filter(f)
+// append, pop, insert and removeAt change the array in place, every other
+// builtin returns a new array and leaves its argument untouched
+var arrayBuiltins = map[string]*object.Builtin{
+	"map": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			arr, fn, err := arrayAndFunctionArgs("map", args)
+			if err != nil {
+				return err
+			}
+			elements := make([]object.Object, len(arr.Elements))
+			for i, element := range arr.Elements {
+				elements[i] = applyFunction(fn, []object.Object{element})
+				if isError(elements[i]) {
+					return elements[i]
+				}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"filter": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			arr, fn, err := arrayAndFunctionArgs("filter", args)
+			if err != nil {
+				return err
+			}
+			elements := []object.Object{}
+			for _, element := range arr.Elements {
+				keep := applyFunction(fn, []object.Object{element})
+				if isError(keep) {
+					return keep
+				}
+				if isTruthy(keep) {
+					elements = append(elements, element)
+				}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"reduce": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 3); err != nil {
+				return err
+			}
+			arr, err := arrayArg("reduce", args, 0)
+			if err != nil {
+				return err
+			}
+			elements := arr.Elements
+			var accumulator object.Object
+			if len(args) == 3 {
+				accumulator = args[2]
+			} else if len(elements) == 0 {
+				return newError("reduce of empty array with no initial value")
+			} else {
+				accumulator, elements = elements[0], elements[1:]
+			}
+			for _, element := range elements {
+				accumulator = applyFunction(args[1], []object.Object{accumulator, element})
+				if isError(accumulator) {
+					return accumulator
+				}
+			}
+			return accumulator
+		},
+	},
+	"find": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			arr, fn, err := arrayAndFunctionArgs("find", args)
+			if err != nil {
+				return err
+			}
+			for _, element := range arr.Elements {
+				found := applyFunction(fn, []object.Object{element})
+				if isError(found) {
+					return found
+				}
+				if isTruthy(found) {
+					return element
+				}
+			}
+			return NULL
+		},
+	},
+	"any": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return arrayPredicate("any", args, true)
+		},
+	},
+	"all": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return arrayPredicate("all", args, false)
+		},
+	},
+	"sort": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			arr, err := arrayArg("sort", args, 0)
+			if err != nil {
+				return err
+			}
+			elements := make([]object.Object, len(arr.Elements))
+			copy(elements, arr.Elements)
+			var sortErr object.Object
+			sort.SliceStable(elements, func(i, j int) bool {
+				if sortErr != nil {
+					return false
+				}
+				var less object.Object
+				if len(args) == 2 {
+					less = compareWith(args[1], elements[i], elements[j])
+				} else {
+					less = lessThan(elements[i], elements[j])
+				}
+				if isError(less) {
+					sortErr = less
+					return false
+				}
+				return less == TRUE
+			})
+			if sortErr != nil {
+				return sortErr
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"reverse": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			arr, err := arrayArg("reverse", args, 0)
+			if err != nil {
+				return err
+			}
+			length := len(arr.Elements)
+			elements := make([]object.Object, length)
+			for i, element := range arr.Elements {
+				elements[length-1-i] = element
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"zip": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 1 {
+				return newError("wrong number of arguments. got=0, want at least 1")
+			}
+			arrays := make([]*object.Array, len(args))
+			length := -1
+			for i := range args {
+				arr, err := arrayArg("zip", args, i)
+				if err != nil {
+					return err
+				}
+				arrays[i] = arr
+				if length < 0 || len(arr.Elements) < length {
+					length = len(arr.Elements)
+				}
+			}
+			elements := make([]object.Object, length)
+			for i := range elements {
+				tuple := make([]object.Object, len(arrays))
+				for j, arr := range arrays {
+					tuple[j] = arr.Elements[i]
+				}
+				elements[i] = &object.Array{Elements: tuple}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"enumerate": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			arr, err := arrayArg("enumerate", args, 0)
+			if err != nil {
+				return err
+			}
+			elements := make([]object.Object, len(arr.Elements))
+			for i, element := range arr.Elements {
+				elements[i] = &object.Array{Elements: []object.Object{&object.Integer{Value: int64(i)}, element}}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"flatten": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			arr, err := arrayArg("flatten", args, 0)
+			if err != nil {
+				return err
+			}
+			depth := int64(1)
+			if len(args) == 2 {
+				if depth, err = integerArg("flatten", args, 1); err != nil {
+					return err
+				}
+			}
+			return &object.Array{Elements: flattenElements(arr.Elements, depth)}
+		},
+	},
+	"unique": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			arr, err := arrayArg("unique", args, 0)
+			if err != nil {
+				return err
+			}
+			// strings, booleans and integers are looked up in a hash. everything else is
+			// compared one by one, since it may equal a value of another type, as 1.0 equals 1
+			elements := []object.Object{}
+			seen := object.NewHash()
+			others := []object.Object{}
+			for _, element := range arr.Elements {
+				switch element := element.(type) {
+				case *object.String, *object.Boolean, *object.Integer:
+					key := element.(object.Hashable)
+					if _, ok := seen.Get(key); ok || indexOfElement(others, element) >= 0 {
+						continue
+					}
+					seen.Set(key, TRUE)
+				default:
+					if indexOfElement(elements, element) >= 0 {
+						continue
+					}
+					others = append(others, element)
+				}
+				elements = append(elements, element)
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"contains": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 2); err != nil {
+				return err
+			}
+			arr, err := arrayArg("contains", args, 0)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(indexOfElement(arr.Elements, args[1]) >= 0)
+		},
+	},
+	"indexOf": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 2); err != nil {
+				return err
+			}
+			arr, err := arrayArg("indexOf", args, 0)
+			if err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(indexOfElement(arr.Elements, args[1]))}
+		},
+	},
+	"concat": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			elements := []object.Object{}
+			for i := range args {
+				arr, err := arrayArg("concat", args, i)
+				if err != nil {
+					return err
+				}
+				elements = append(elements, arr.Elements...)
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"join": stringBuiltins["join"],
+	"append": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 1 {
+				return newError("wrong number of arguments. got=0, want at least 1")
+			}
+			arr, err := arrayArg("append", args, 0)
+			if err != nil {
+				return err
+			}
+			arr.Elements = append(arr.Elements, args[1:]...)
+			return arr
+		},
+	},
+	"pop": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			arr, err := arrayArg("pop", args, 0)
+			if err != nil {
+				return err
+			}
+			if len(arr.Elements) == 0 {
+				return newError("pop from empty array")
+			}
+			index := int64(len(arr.Elements) - 1)
+			if len(args) == 2 {
+				if index, err = elementIndex("pop", args, 1, len(arr.Elements)); err != nil {
+					return err
+				}
+			}
+			return removeElement(arr, int(index))
+		},
+	},
+	"insert": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 3, 3); err != nil {
+				return err
+			}
+			arr, err := arrayArg("insert", args, 0)
+			if err != nil {
+				return err
+			}
+			// the element ends up before the one at index, so inserting at len(arr)
+			// appends. negative indices count from the end as for other builtins:
+			// -1 inserts before the last element and -len(arr) at the front
+			index, err := integerArg("insert", args, 1)
+			if err != nil {
+				return err
+			}
+			if index < 0 {
+				index += int64(len(arr.Elements))
+			}
+			if index < 0 || index > int64(len(arr.Elements)) {
+				return newError("index %d out of range for `insert`", args[1].(*object.Integer).Value)
+			}
+			arr.Elements = append(arr.Elements, nil)
+			copy(arr.Elements[index+1:], arr.Elements[index:])
+			arr.Elements[index] = args[2]
+			return arr
+		},
+	},
+	"removeAt": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 2); err != nil {
+				return err
+			}
+			arr, err := arrayArg("removeAt", args, 0)
+			if err != nil {
+				return err
+			}
+			index, err := elementIndex("removeAt", args, 1, len(arr.Elements))
+			if err != nil {
+				return err
+			}
+			return removeElement(arr, int(index))
+		},
+	},
+}
+
+func init() {
+	registerBuiltinMethods(object.ARRAY_OBJ, arrayBuiltins)
+}
+
+//arrayArg returns the argument at index i, which must be an Array
+func arrayArg(builtin string, args []object.Object, i int) (*object.Array, *object.Error) {
+	arr, ok := args[i].(*object.Array)
+	if !ok {
+		return nil, newError("argument %d to `%s` must be ARRAY, got %s", i+1, builtin, args[i].Type())
+	}
+	return arr, nil
+}
+
+//arrayAndFunctionArgs validates builtins called with an array and a function
+func arrayAndFunctionArgs(builtin string, args []object.Object) (*object.Array, object.Object, *object.Error) {
+	if err := checkArgCount(args, 2, 2); err != nil {
+		return nil, nil, err
+	}
+	arr, err := arrayArg(builtin, args, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return arr, args[1], nil
+}
+
+//arrayPredicate implements any and all. without a function the elements themselves are tested.
+// stop is the result that ends the search early, true for any and false for all
+func arrayPredicate(builtin string, args []object.Object, stop bool) object.Object {
+	if err := checkArgCount(args, 1, 2); err != nil {
+		return err
+	}
+	arr, err := arrayArg(builtin, args, 0)
+	if err != nil {
+		return err
+	}
+	for _, element := range arr.Elements {
+		result := element
+		if len(args) == 2 {
+			result = applyFunction(args[1], []object.Object{element})
+			if isError(result) {
+				return result
+			}
+		}
+		if isTruthy(result) == stop {
+			return nativeBoolToBooleanObject(stop)
+		}
+	}
+	return nativeBoolToBooleanObject(!stop)
+}
+
+//compareWith calls a sort comparator. the comparator returns either a boolean
+// telling whether a comes before b, or an integer that is negative when it does
+func compareWith(comparator, a, b object.Object) object.Object {
+	result := applyFunction(comparator, []object.Object{a, b})
+	switch result := result.(type) {
+	case *object.Error:
+		return result
+	case *object.Boolean:
+		return result
+	case *object.Integer:
+		return nativeBoolToBooleanObject(result.Value < 0)
+	default:
+		return newError("sort comparator must return BOOLEAN or INTEGER, got %s", result.Type())
+	}
+}
+
+//flattenElements splices nested arrays into their parent up to the given depth
+func flattenElements(elements []object.Object, depth int64) []object.Object {
+	flat := []object.Object{}
+	for _, element := range elements {
+		if inner, ok := element.(*object.Array); ok && depth > 0 {
+			flat = append(flat, flattenElements(inner.Elements, depth-1)...)
+		} else {
+			flat = append(flat, element)
+		}
+	}
+	return flat
+}
+
+//indexOfElement returns the position of the first element equal to obj, or -1
+func indexOfElement(elements []object.Object, obj object.Object) int {
+	for i, element := range elements {
+		if objectsEqual(element, obj) {
+			return i
+		}
+	}
+	return -1
+}
+
+//elementIndex returns the integer argument at position i as an index into an array
+// of the given length. negative indices count from the end
+func elementIndex(builtin string, args []object.Object, i int, length int) (int64, *object.Error) {
+	index, err := integerArg(builtin, args, i)
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 {
+		index += int64(length)
+	}
+	if index < 0 || index >= int64(length) {
+		return 0, newError("index %d out of range for `%s`", args[i].(*object.Integer).Value, builtin)
+	}
+	return index, nil
+}
+
+//removeElement removes and returns the element at index, shifting the rest down
+func removeElement(arr *object.Array, index int) object.Object {
+	removed := arr.Elements[index]
+	copy(arr.Elements[index:], arr.Elements[index+1:])
+	arr.Elements[len(arr.Elements)-1] = nil
+	arr.Elements = arr.Elements[:len(arr.Elements)-1]
+	return removed
+}
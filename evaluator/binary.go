@@ -0,0 +1,252 @@
+package evaluator
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"monkey/object"
+)
+
+func init() {
+	registerModule("binary", func() *object.Module {
+		members := map[string]object.Object{}
+		for name, builtin := range binaryBuiltins {
+			members[name] = builtin
+		}
+		return newModule("binary", members)
+	})
+}
+
+//binaryBuiltins are the functions of the binary module. data arguments are bytes,
+// or strings which stand for their utf-8 bytes
+var binaryBuiltins = map[string]*object.Builtin{
+	// encodeBase64(data, urlSafe) uses the standard alphabet unless urlSafe is true
+	"encodeBase64": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			data, err := bytesArg("encodeBase64", args, 0)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: base64Encoding(args).EncodeToString(data)}
+		},
+	},
+	"decodeBase64": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			text, err := stringArg("decodeBase64", args, 0)
+			if err != nil {
+				return err
+			}
+			data, decodeErr := base64Encoding(args).DecodeString(text)
+			if decodeErr != nil {
+				return newError("invalid base64: %s", decodeErr)
+			}
+			return &object.Bytes{Value: data}
+		},
+	},
+	"encodeHex": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			data, err := bytesArg("encodeHex", args, 0)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: hex.EncodeToString(data)}
+		},
+	},
+	"decodeHex": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			text, err := stringArg("decodeHex", args, 0)
+			if err != nil {
+				return err
+			}
+			data, decodeErr := hex.DecodeString(text)
+			if decodeErr != nil {
+				return newError("invalid hex: %s", decodeErr)
+			}
+			return &object.Bytes{Value: data}
+		},
+	},
+	// pack(format, values...) packs integers into bytes. eg. pack("<Hi", 1, -2)
+	// see packFormat for the format
+	"pack": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return newError("wrong number of arguments. got=0, want=1 or more")
+			}
+			order, fields, err := packFormat("pack", args)
+			if err != nil {
+				return err
+			}
+			if len(fields) != len(args)-1 {
+				return newError("format needs %d values, got %d", len(fields), len(args)-1)
+			}
+			data := []byte{}
+			for i, field := range fields {
+				value := args[i+1]
+				if !isInteger(value) {
+					return newError("values to pack must be INTEGER, got %s", value.Type())
+				}
+				n := toBigInt(value)
+				if n.Cmp(field.min()) < 0 || n.Cmp(field.max()) > 0 {
+					return newError("%s does not fit in format %c", n, field.code)
+				}
+				// two's complement of the value in size bytes
+				unsigned := new(big.Int).Set(n)
+				if unsigned.Sign() < 0 {
+					unsigned.Add(unsigned, new(big.Int).Lsh(big.NewInt(1), uint(8*field.size)))
+				}
+				data = appendUint(data, order, field.size, unsigned.Uint64())
+			}
+			return &object.Bytes{Value: data}
+		},
+	},
+	// unpack(format, data) returns the tuple of integers packed in data
+	"unpack": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 2); err != nil {
+				return err
+			}
+			order, fields, err := packFormat("unpack", args)
+			if err != nil {
+				return err
+			}
+			data, err := bytesArg("unpack", args, 1)
+			if err != nil {
+				return err
+			}
+			size := 0
+			for _, field := range fields {
+				size += field.size
+			}
+			if size != len(data) {
+				return newError("format needs %d bytes, got %d", size, len(data))
+			}
+			values := make([]object.Object, len(fields))
+			for i, field := range fields {
+				unsigned := readUint(data[:field.size], order)
+				data = data[field.size:]
+				n := new(big.Int).SetUint64(unsigned)
+				if field.signed && n.Cmp(field.max()) > 0 {
+					n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*field.size)))
+				}
+				values[i] = normalizeBigInt(n)
+			}
+			return &object.Tuple{Elements: values}
+		},
+	},
+}
+
+//packField one integer of a pack format
+type packField struct {
+	code   byte
+	size   int
+	signed bool
+}
+
+func (f packField) max() *big.Int {
+	bits := uint(8 * f.size)
+	if f.signed {
+		bits--
+	}
+	return new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits), big.NewInt(1))
+}
+
+func (f packField) min() *big.Int {
+	if !f.signed {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), uint(8*f.size-1)))
+}
+
+//packCodes are the integer codes of pack formats. lower case codes are signed
+var packCodes = map[byte]packField{
+	'b': {'b', 1, true},
+	'B': {'B', 1, false},
+	'h': {'h', 2, true},
+	'H': {'H', 2, false},
+	'i': {'i', 4, true},
+	'I': {'I', 4, false},
+	'q': {'q', 8, true},
+	'Q': {'Q', 8, false},
+}
+
+//packFormat parses the format at args[0]. it may start with < for little endian or
+// > for big endian (the default), followed by one code per integer:
+// b/B 8 bits, h/H 16 bits, i/I 32 bits, q/Q 64 bits, lower case being signed
+func packFormat(builtin string, args []object.Object) (binary.ByteOrder, []packField, *object.Error) {
+	format, err := stringArg(builtin, args, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	codes := format
+	if len(codes) > 0 && (codes[0] == '<' || codes[0] == '>') {
+		if codes[0] == '<' {
+			order = binary.LittleEndian
+		}
+		codes = codes[1:]
+	}
+	fields := []packField{}
+	for i := 0; i < len(codes); i++ {
+		field, ok := packCodes[codes[i]]
+		if !ok {
+			return nil, nil, newError("unknown code %c in format %q", codes[i], format)
+		}
+		fields = append(fields, field)
+	}
+	return order, fields, nil
+}
+
+//appendUint appends the low size bytes of value to data in order
+func appendUint(data []byte, order binary.ByteOrder, size int, value uint64) []byte {
+	buf := make([]byte, 8)
+	if order == binary.ByteOrder(binary.LittleEndian) {
+		binary.LittleEndian.PutUint64(buf, value)
+		return append(data, buf[:size]...)
+	}
+	binary.BigEndian.PutUint64(buf, value)
+	return append(data, buf[8-size:]...)
+}
+
+//readUint reads an unsigned integer stored in data in order
+func readUint(data []byte, order binary.ByteOrder) uint64 {
+	buf := make([]byte, 8)
+	if order == binary.ByteOrder(binary.LittleEndian) {
+		copy(buf, data)
+		return binary.LittleEndian.Uint64(buf)
+	}
+	copy(buf[8-len(data):], data)
+	return binary.BigEndian.Uint64(buf)
+}
+
+//base64Encoding returns the url safe encoding when the optional second argument is true
+func base64Encoding(args []object.Object) *base64.Encoding {
+	if len(args) == 2 && isTruthy(args[1]) {
+		return base64.URLEncoding
+	}
+	return base64.StdEncoding
+}
+
+//bytesArg returns the data of the argument at index i, which must be BYTES or a STRING
+func bytesArg(builtin string, args []object.Object, i int) ([]byte, *object.Error) {
+	switch arg := args[i].(type) {
+	case *object.Bytes:
+		return arg.Value, nil
+	case *object.String:
+		return []byte(arg.Value), nil
+	default:
+		return nil, newError("argument %d to `%s` must be BYTES or STRING, got %s", i+1, builtin, arg.Type())
+	}
+}
@@ -4,6 +4,25 @@ import (
 	"monkey/object"
 )
 
+//nativeClass returns the class of objects implemented in Go, such as random.Generator,
+// so that type and isinstance work on them. calling the class calls constructor; a
+// class without one cannot be called, its instances come from other builtins
+func nativeClass(name string, constructor *object.Builtin) *object.Class {
+	if constructor == nil {
+		constructor = &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				return newError("%s objects cannot be created directly", name)
+			},
+		}
+	}
+	return &object.Class{Name: name, Env: object.NewEnvironment().SetMultiple(map[string]object.Object{"__New__": constructor})}
+}
+
+//newNativeInstance returns an instance of a native class holding members
+func newNativeInstance(class *object.Class, members map[string]object.Object) *object.ClassInstance {
+	return &object.ClassInstance{Name: class.Name, Env: object.NewEnvironment().SetMultiple(members), Class: class}
+}
+
 var OBJECT = &object.ClassInstance{
 	Name: "BuiltinObject",
 	Env: object.NewEnvironment().SetMultiple(
@@ -26,31 +45,4 @@ var OBJECT = &object.ClassInstance{
 			},
 		},
 	),
-} /*
-var REFLECTOR = &object.ClassInstance{
-	Name: "BuiltinReflector",
-	Env: object.NewEnvironment().SetMultiple(
-		map[string]object.Object{
-			"get_method": &object.Builtin{
-				Fn: func(args ...object.Object) object.Object {
-					if len(args) < 2 {
-						return newError("__str__ takes only one argument, but more than one was given")
-					}
-					httpServerObject := http.ReadRequest
-					argObject, ok := args[0].(*object.ClassInstance)
-					e := reflect.ValueOf(http)
-
-					if !ok {
-						return newError("The first argument to reflector must be a class Instance")
-					}
-					argObject.Env.Set("__value__", httpServerObject)
-					return &object.String{Value: args[0].Inspect()}
-				},
-			},
-		},
-	),
-}
-
-type Reflector struct {
 }
-*/
@@ -3,6 +3,8 @@ package evaluator
 import (
 	"fmt"
 	"monkey/object"
+	"sort"
+	"unicode/utf8"
 )
 
 var builtins = map[string]*object.Builtin{
@@ -19,23 +21,9 @@ var builtins = map[string]*object.Builtin{
 	"env": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments. to str got=%d, want=1", len(args))
+				return newError("wrong number of arguments. to env got=%d, want=1", len(args))
 			}
-			obj := args[0]
-			switch obj.(type) {
-			case *object.ClassInstance:
-				newObj, _ := obj.(*object.ClassInstance)
-				fmt.Println(newObj.Env)
-			case *object.Class:
-				newObj, _ := obj.(*object.Class)
-				fmt.Println(newObj.Env)
-			case *object.Module:
-				newObj, _ := obj.(*object.Module)
-				fmt.Println(newObj.Env)
-			default:
-				fmt.Println("Error : >>> Object has no Env")
-			}
-			return NULL
+			return objectVars("env", args[0])
 		},
 	},
 	"len": &object.Builtin{
@@ -47,7 +35,17 @@ var builtins = map[string]*object.Builtin{
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
 			case *object.String:
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+			case *object.Enum:
+				return &object.Integer{Value: int64(len(arg.Members))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(arg.Len())}
+			case *object.Bytes:
 				return &object.Integer{Value: int64(len(arg.Value))}
+			case *object.Set:
+				return &object.Integer{Value: int64(arg.Len())}
+			case *object.Tuple:
+				return &object.Integer{Value: int64(len(arg.Elements))}
 			default:
 				return newError("argument to `len` not supported, got %s", args[0].Type())
 			}
@@ -116,6 +114,10 @@ var builtins = map[string]*object.Builtin{
 
 			// create and return a new array so that modification to the new array
 			// does not affect the old array.
+			// this makes push O(n), and a loop of pushes quadratic. it is kept that way
+			// because scripts rely on the argument staying unchanged; sharing spare
+			// capacity instead would let two pushes onto the same array overwrite each
+			// other's element. `append` grows the array in place in amortised O(1)
 
 			newElements := make([]object.Object, length+1, length+1)
 			copy(newElements, arr.Elements)
@@ -131,44 +133,322 @@ var builtins = map[string]*object.Builtin{
 			return NULL
 		},
 	},
-	"hasattr": &object.Builtin{
+	"type": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			return typeOf(args[0])
+		},
+	},
+	"isinstance": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 2 {
 				return newError("wrong number of arguments. got=%d, want=2", len(args))
 			}
-			left, ok := args[0].(*object.ClassInstance)
-			if !ok {
-				return newError("Argument 1 must be a `CLASSINSTANCE`")
+			switch typ := args[1].(type) {
+			case *object.Class:
+				instance, ok := args[0].(*object.ClassInstance)
+				if !ok || instance.Class == nil {
+					return FALSE
+				}
+				return nativeBoolToBooleanObject(instance.Class.IsSubclassOf(typ))
+			case *object.Record:
+				record, ok := args[0].(*object.RecordInstance)
+				return nativeBoolToBooleanObject(ok && record.Record == typ)
+			case *object.Enum:
+				member, ok := args[0].(*object.EnumMember)
+				return nativeBoolToBooleanObject(ok && member.Enum == typ)
+			case *object.TypeObject:
+				return nativeBoolToBooleanObject(args[0].Type() == typ.Name)
+			default:
+				return newError("second argument to `isinstance` must be CLASS, RECORD, ENUM or TYPE, got %s", args[1].Type())
 			}
-			right, ok := args[0].(*object.String)
+		},
+	},
+	"implements": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			iface, ok := args[1].(*object.Interface)
 			if !ok {
-				return newError("Argument 2 must be a `STRING`")
+				return newError("second argument to `implements` must be INTERFACE, got %s", args[1].Type())
+			}
+			var class *object.Class
+			switch obj := args[0].(type) {
+			case *object.ClassInstance:
+				class = obj.Class
+			case *object.Class:
+				class = obj
 			}
-			_, ok = left.Env.Closed().Get(right.Inspect())
-			if ok {
+			if class == nil {
+				return FALSE
+			}
+			return nativeBoolToBooleanObject(len(missingMethods(class, iface)) == 0)
+		},
+	},
+	"callable": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			switch args[0].(type) {
+			case *object.Function, *object.Builtin, *object.BoundMethod, *object.Class, *object.Record:
 				return TRUE
+			default:
+				return FALSE
 			}
-			return FALSE
-
+		},
+	},
+	"dir": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			names := []string{}
+			switch obj := args[0].(type) {
+			case *object.ClassInstance:
+				names = obj.Env.Names()
+				if obj.Class != nil {
+					names = mergeNames(names, obj.Class.Env.Names())
+				}
+			case *object.Class:
+				names = obj.Env.Names()
+			case *object.Module:
+				names = obj.Env.Names()
+			}
+			for name := range builtinMethods[args[0].Type()] {
+				names = append(names, name)
+			}
+			names = mergeNames(names, nil)
+			names = publicNames(names)
+			elements := make([]object.Object, len(names))
+			for i, name := range names {
+				elements[i] = &object.String{Value: name}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"vars": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			return objectVars("vars", args[0])
+		},
+	},
+	"getattr": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+			}
+			env, name, err := attributeArgs("getattr", args[0], args[1])
+			if err != nil {
+				return err
+			}
+			if value, ok := env.Get(name); ok {
+				return value
+			}
+			if len(args) == 3 {
+				return args[2]
+			}
+			return newError("%s has no attribute %s", args[0].Inspect(), name)
+		},
+	},
+	"hasattr": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			if name, ok := args[1].(*object.String); ok && isPrivate(name.Value) {
+				return FALSE
+			}
+			env, name, err := attributeArgs("hasattr", args[0], args[1])
+			if err != nil {
+				return err
+			}
+			_, ok := env.Get(name)
+			return nativeBoolToBooleanObject(ok)
 		},
 	},
 	"setattr": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=3", len(args))
+			}
+			env, name, err := attributeArgs("setattr", args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return env.Set(name, args[2])
+		},
+	},
+	"delattr": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
 				return newError("wrong number of arguments. got=%d, want=2", len(args))
 			}
-			left, ok := args[0].(*object.ClassInstance)
-			if !ok {
-				return newError("Argument 1 must be a `CLASSINSTANCE`")
+			env, name, err := attributeArgs("delattr", args[0], args[1])
+			if err != nil {
+				return err
 			}
-			right, ok := args[1].(*object.String)
-			if !ok {
-				return newError("Argument 2 must be a `STRING`")
+			if !env.Delete(name) {
+				return newError("%s has no attribute %s", args[0].Inspect(), name)
 			}
-			left.Env.Set(right.Inspect(), args[2])
-			fmt.Println(left.Env.Get(right.Inspect()))
-			return args[2]
-
+			return NULL
 		},
 	},
 }
+
+func init() {
+	// try calls back into the evaluator so it cannot be part of the builtins literal
+	builtins["try"] = &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return newError("wrong number of arguments. got=0, want=1 or more")
+			}
+			return tryCall(args[0], args[1:])
+		},
+	}
+}
+
+//tryCall implements try(fn, args...). it calls fn and returns the tuple (result, null),
+// or (null, message) when the call fails, so errors can be handled instead of ending
+// the program. eg. let (text, err) = try(fs.readFile, "notes.txt");
+func tryCall(fn object.Object, args []object.Object) object.Object {
+	result := applyFunction(fn, args)
+	if err, ok := result.(*object.Error); ok {
+		return &object.Tuple{Elements: []object.Object{NULL, &object.String{Value: err.Message}}}
+	}
+	return &object.Tuple{Elements: []object.Object{result, NULL}}
+}
+
+//builtinTypes caches the type objects of builtin objects so that
+// type(1) == type(2) holds
+var builtinTypes = map[object.ObjectType]*object.TypeObject{}
+
+//typeOf returns the class, record or enum of an object, or the type object of any other object
+func typeOf(obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.ClassInstance:
+		if obj.Class != nil {
+			return obj.Class
+		}
+	case *object.RecordInstance:
+		return obj.Record
+	case *object.EnumMember:
+		return obj.Enum
+	}
+	typ, ok := builtinTypes[obj.Type()]
+	if !ok {
+		typ = &object.TypeObject{Name: obj.Type()}
+		builtinTypes[obj.Type()] = typ
+	}
+	return typ
+}
+
+//attributeEnv returns the environment holding the attributes of an object.
+// instance lookups fall back to the class, class and module lookups do not
+// escape to the scope they were defined in
+func attributeEnv(obj object.Object) (*object.Environment, bool) {
+	switch obj := obj.(type) {
+	case *object.ClassInstance:
+		return obj.Env, true
+	case *object.Class:
+		return obj.Env.Closed(), true
+	case *object.Module:
+		return obj.Env.Closed(), true
+	default:
+		return nil, false
+	}
+}
+
+//attributeArgs validates the object and name arguments of the *attr builtins.
+// builtins are evaluated outside of any method so private members are refused
+func attributeArgs(builtin string, obj object.Object, name object.Object) (*object.Environment, string, *object.Error) {
+	env, ok := attributeEnv(obj)
+	if !ok {
+		return nil, "", newError("first argument to `%s` must be CLASS_INSTANCE, CLASS or MODULE, got %s", builtin, obj.Type())
+	}
+	str, ok := name.(*object.String)
+	if !ok {
+		return nil, "", newError("second argument to `%s` must be STRING, got %s", builtin, name.Type())
+	}
+	if isPrivate(str.Value) {
+		return nil, "", newError("cannot access private member %s of %s", str.Value, obj.Inspect())
+	}
+	return env, str.Value, nil
+}
+
+//objectVars returns a hash of the names defined directly on an object
+func objectVars(builtin string, obj object.Object) object.Object {
+	env, ok := attributeEnv(obj)
+	if !ok {
+		return newError("argument to `%s` must be CLASS_INSTANCE, CLASS or MODULE, got %s", builtin, obj.Type())
+	}
+	own := env.Closed()
+	if instance, ok := obj.(*object.ClassInstance); ok {
+		own = instance.Env.Closed()
+	}
+	hash := object.NewHash()
+	for _, name := range publicNames(own.Names()) {
+		value, _ := own.Get(name)
+		hash.Set(&object.String{Value: name}, value)
+	}
+	return hash
+}
+
+//publicNames filters out the private names of a list of names
+func publicNames(names []string) []string {
+	public := []string{}
+	for _, name := range names {
+		if !isPrivate(name) {
+			public = append(public, name)
+		}
+	}
+	return public
+}
+
+//mergeNames merges two sorted lists of names, dropping duplicates
+func mergeNames(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	merged := []string{}
+	for _, name := range append(a, b...) {
+		if !seen[name] {
+			seen[name] = true
+			merged = append(merged, name)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+//checkArgCount checks that a builtin got between min and max arguments
+func checkArgCount(args []object.Object, min, max int) *object.Error {
+	if len(args) >= min && len(args) <= max {
+		return nil
+	}
+	if min == max {
+		return newError("wrong number of arguments. got=%d, want=%d", len(args), min)
+	}
+	return newError("wrong number of arguments. got=%d, want=%d to %d", len(args), min, max)
+}
+
+//stringArg returns the value of the argument at index i, which must be a String
+func stringArg(builtin string, args []object.Object, i int) (string, *object.Error) {
+	str, ok := args[i].(*object.String)
+	if !ok {
+		return "", newError("argument %d to `%s` must be STRING, got %s", i+1, builtin, args[i].Type())
+	}
+	return str.Value, nil
+}
+
+//integerArg returns the value of the argument at index i, which must be an Integer
+func integerArg(builtin string, args []object.Object, i int) (int64, *object.Error) {
+	integer, ok := args[i].(*object.Integer)
+	if !ok {
+		return 0, newError("argument %d to `%s` must be INTEGER, got %s", i+1, builtin, args[i].Type())
+	}
+	return integer.Value, nil
+}
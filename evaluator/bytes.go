@@ -0,0 +1,231 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"monkey/object"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+func init() {
+	// bytes(value, encoding) makes bytes from a string, an array of byte values or other bytes
+	builtins["bytes"] = &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			switch arg := args[0].(type) {
+			case *object.String:
+				return encodeStringArgs("bytes", arg.Value, args[1:])
+			case *object.Bytes:
+				return &object.Bytes{Value: append([]byte{}, arg.Value...)}
+			case *object.Array:
+				value := make([]byte, len(arg.Elements))
+				for i, element := range arg.Elements {
+					integer, ok := element.(*object.Integer)
+					if !ok || integer.Value < 0 || integer.Value > 255 {
+						return newError("bytes must be INTEGER from 0 to 255, got %s", element.Inspect())
+					}
+					value[i] = byte(integer.Value)
+				}
+				return &object.Bytes{Value: value}
+			default:
+				return newError("argument 1 to `bytes` must be STRING, BYTES or ARRAY, got %s", arg.Type())
+			}
+		},
+	}
+	registerMethods(object.STRING_OBJ, map[string]*object.Builtin{
+		// encode returns the bytes of a string in an encoding, utf-8 by default
+		"encode": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args[1:], 0, 1); err != nil {
+					return err
+				}
+				return encodeStringArgs("encode", args[0].(*object.String).Value, args[1:])
+			},
+		},
+	})
+	registerMethods(object.BYTES_OBJ, bytesMethods)
+}
+
+//bytesMethods are the methods of bytes. eg. b"hi".decode()
+// the receiver is not counted in argument errors
+var bytesMethods = map[string]*object.Builtin{
+	// decode returns the string the bytes encode, utf-8 by default
+	"decode": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args[1:], 0, 1); err != nil {
+				return err
+			}
+			encoding := "utf-8"
+			if len(args) == 2 {
+				var err *object.Error
+				if encoding, err = stringArg("decode", args[1:], 0); err != nil {
+					return err
+				}
+			}
+			text, err := decodeBytes(args[0].(*object.Bytes).Value, encoding)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: text}
+		},
+	},
+	"hex": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args[1:], 0, 0); err != nil {
+				return err
+			}
+			return &object.String{Value: hex.EncodeToString(args[0].(*object.Bytes).Value)}
+		},
+	},
+	"toArray": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args[1:], 0, 0); err != nil {
+				return err
+			}
+			value := args[0].(*object.Bytes).Value
+			elements := make([]object.Object, len(value))
+			for i, b := range value {
+				elements[i] = &object.Integer{Value: int64(b)}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+}
+
+//evalBytesIndexExpression returns the byte at index as an integer, null when out of range
+func evalBytesIndexExpression(b *object.Bytes, index int64) object.Object {
+	if index < 0 || index >= int64(len(b.Value)) {
+		return NULL
+	}
+	return &object.Integer{Value: int64(b.Value[index])}
+}
+
+//evalBytesInExpression checks for a byte value or a run of bytes. eg. 255 in b or b"PNG" in b
+func evalBytesInExpression(left object.Object, right *object.Bytes) object.Object {
+	switch left := left.(type) {
+	case *object.Integer:
+		return nativeBoolToBooleanObject(left.Value >= 0 && left.Value <= 255 && bytes.IndexByte(right.Value, byte(left.Value)) >= 0)
+	case *object.Bytes:
+		return nativeBoolToBooleanObject(bytes.Contains(right.Value, left.Value))
+	default:
+		return newError("type mismatch: %s in BYTES", left.Type())
+	}
+}
+
+//evalBytesInfixExpression evaluates concatenation and comparison of bytes
+func evalBytesInfixExpression(operator string, left, right *object.Bytes) object.Object {
+	switch operator {
+	case "+":
+		value := make([]byte, 0, len(left.Value)+len(right.Value))
+		return &object.Bytes{Value: append(append(value, left.Value...), right.Value...)}
+	case "==":
+		return nativeBoolToBooleanObject(bytes.Equal(left.Value, right.Value))
+	case "!=":
+		return nativeBoolToBooleanObject(!bytes.Equal(left.Value, right.Value))
+	case "<":
+		return nativeBoolToBooleanObject(bytes.Compare(left.Value, right.Value) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(bytes.Compare(left.Value, right.Value) > 0)
+	default:
+		return newError("unknown operator: BYTES %s BYTES", operator)
+	}
+}
+
+//encodeStringArgs encodes text in the encoding given as the only element of args, utf-8 if there is none
+func encodeStringArgs(builtin string, text string, args []object.Object) object.Object {
+	encoding := "utf-8"
+	if len(args) == 1 {
+		var err *object.Error
+		if encoding, err = stringArg(builtin, args, 0); err != nil {
+			return err
+		}
+	}
+	value, err := encodeString(text, encoding)
+	if err != nil {
+		return err
+	}
+	return &object.Bytes{Value: value}
+}
+
+//normalizeEncoding lets encodings be written in any case, with or without dashes. eg. UTF-8 or utf8
+func normalizeEncoding(encoding string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(encoding))
+}
+
+//encodeString returns the bytes of text in encoding: utf-8, ascii, latin-1, utf-16le or utf-16be
+func encodeString(text string, encoding string) ([]byte, *object.Error) {
+	switch normalizeEncoding(encoding) {
+	case "utf8":
+		return []byte(text), nil
+	case "ascii", "latin1", "iso88591":
+		limit := rune(0xff)
+		if normalizeEncoding(encoding) == "ascii" {
+			limit = 0x7f
+		}
+		value := make([]byte, 0, len(text))
+		for _, r := range text {
+			if r > limit {
+				return nil, newError("cannot encode %q as %s", r, encoding)
+			}
+			value = append(value, byte(r))
+		}
+		return value, nil
+	case "utf16le", "utf16be":
+		var order binary.ByteOrder = binary.LittleEndian
+		if normalizeEncoding(encoding) == "utf16be" {
+			order = binary.BigEndian
+		}
+		units := utf16.Encode([]rune(text))
+		value := make([]byte, 2*len(units))
+		for i, unit := range units {
+			order.PutUint16(value[2*i:], unit)
+		}
+		return value, nil
+	default:
+		return nil, newError("unknown encoding %s", encoding)
+	}
+}
+
+//decodeBytes returns the text value holds in encoding, failing on data that is not valid in it
+func decodeBytes(value []byte, encoding string) (string, *object.Error) {
+	switch normalizeEncoding(encoding) {
+	case "utf8":
+		for i := 0; i < len(value); {
+			r, size := utf8.DecodeRune(value[i:])
+			if r == utf8.RuneError && size == 1 {
+				return "", newError("invalid %s data at byte %d", encoding, i)
+			}
+			i += size
+		}
+		return string(value), nil
+	case "ascii", "latin1", "iso88591":
+		runes := make([]rune, len(value))
+		for i, b := range value {
+			if b > 0x7f && normalizeEncoding(encoding) == "ascii" {
+				return "", newError("invalid %s data at byte %d", encoding, i)
+			}
+			runes[i] = rune(b)
+		}
+		return string(runes), nil
+	case "utf16le", "utf16be":
+		if len(value)%2 != 0 {
+			return "", newError("invalid %s data: odd number of bytes", encoding)
+		}
+		var order binary.ByteOrder = binary.LittleEndian
+		if normalizeEncoding(encoding) == "utf16be" {
+			order = binary.BigEndian
+		}
+		units := make([]uint16, len(value)/2)
+		for i := range units {
+			units[i] = order.Uint16(value[2*i:])
+		}
+		return string(utf16.Decode(units)), nil
+	default:
+		return "", newError("unknown encoding %s", encoding)
+	}
+}
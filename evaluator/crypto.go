@@ -0,0 +1,143 @@
+package evaluator
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"hash"
+	"monkey/object"
+)
+
+//maxRandomBytes is the most bytes one call of randomBytes returns
+const maxRandomBytes = 1 << 20
+
+//hashAlgorithms are the digests of the crypto module, also usable with hmac
+var hashAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+func init() {
+	registerModule("crypto", func() *object.Module {
+		members := map[string]object.Object{}
+		for name, builtin := range cryptoBuiltins {
+			members[name] = builtin
+		}
+		for name, algorithm := range hashAlgorithms {
+			members[name] = digestBuiltin(name, algorithm)
+		}
+		return newModule("crypto", members)
+	})
+}
+
+//cryptoBuiltins are the functions of the crypto module besides the digests.
+// data arguments are bytes, or strings which stand for their utf-8 bytes
+var cryptoBuiltins = map[string]*object.Builtin{
+	// hmac(algorithm, key, data) returns the HMAC of data. eg. hmac("sha256", secret, body)
+	"hmac": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 3, 3); err != nil {
+				return err
+			}
+			name, err := stringArg("hmac", args, 0)
+			if err != nil {
+				return err
+			}
+			algorithm, ok := hashAlgorithms[name]
+			if !ok {
+				return newError("unknown hash algorithm %s", name)
+			}
+			key, err := bytesArg("hmac", args, 1)
+			if err != nil {
+				return err
+			}
+			data, err := bytesArg("hmac", args, 2)
+			if err != nil {
+				return err
+			}
+			mac := hmac.New(algorithm, key)
+			mac.Write(data)
+			return &object.Bytes{Value: mac.Sum(nil)}
+		},
+	},
+	// compare checks two secrets for equality in time that does not depend on their content
+	"compare": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 2); err != nil {
+				return err
+			}
+			a, err := bytesArg("compare", args, 0)
+			if err != nil {
+				return err
+			}
+			b, err := bytesArg("compare", args, 1)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(subtle.ConstantTimeCompare(a, b) == 1)
+		},
+	},
+	// randomBytes returns n bytes from the operating system's secure random source, at most maxRandomBytes
+	"randomBytes": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			n, err := integerArg("randomBytes", args, 0)
+			if err != nil {
+				return err
+			}
+			if n < 0 {
+				return newError("argument to `randomBytes` must not be negative")
+			}
+			if n > maxRandomBytes {
+				return newError("argument to `randomBytes` must be at most %d", maxRandomBytes)
+			}
+			data := make([]byte, n)
+			if _, readErr := rand.Read(data); readErr != nil {
+				return newError("randomBytes: %s", readErr)
+			}
+			return &object.Bytes{Value: data}
+		},
+	},
+	// uuid returns a random (version 4) UUID. eg. 0b6d5a3e-8c1f-4b9a-9f0e-2d7c4a1b3e5f
+	"uuid": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 0, 0); err != nil {
+				return err
+			}
+			u := make([]byte, 16)
+			if _, err := rand.Read(u); err != nil {
+				return newError("uuid: %s", err)
+			}
+			u[6] = u[6]&0x0f | 0x40 // version 4
+			u[8] = u[8]&0x3f | 0x80 // RFC 4122 variant
+			return &object.String{Value: fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])}
+		},
+	},
+}
+
+//digestBuiltin returns a builtin computing the digest of its argument with algorithm
+func digestBuiltin(builtin string, algorithm func() hash.Hash) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			data, err := bytesArg(builtin, args, 0)
+			if err != nil {
+				return err
+			}
+			h := algorithm()
+			h.Write(data)
+			return &object.Bytes{Value: h.Sum(nil)}
+		},
+	}
+}
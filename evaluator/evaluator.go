@@ -3,10 +3,13 @@ package evaluator
 import (
 	"fmt"
 	"io/ioutil"
+	"math"
+	"math/big"
 	"monkey/ast"
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parser"
+	"strings"
 )
 
 var (
@@ -66,21 +69,34 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			if !ok {
 				return newError("unknown identifier: %s", node.Name.Value)
 			}
+			if record, ok := cls.(*object.RecordInstance); ok {
+				return newError("record %s is immutable, use with() to change %s", record.Record.Name, property.Value)
+			}
 			classInstance, ok := cls.(*object.ClassInstance)
 			if !ok {
 				return newError("Dot assignment allowed only on class Instances.Cannot use dot assignment on %T: %s", cls, node.Name.Value)
 			}
+			if isPrivate(property.Value) && !isSelf(classInstance, env) {
+				return newError("cannot access private member %s of %s", property.Value, classInstance.Name)
+			}
 			_, ok = classInstance.Env.Get(property.Value)
 			if !ok {
 				return newError("%s is not an instance variable of class %s", property.Value, cls.Inspect())
 			}
 			classInstance.Env.Set(property.Value, val)
+		} else if node.Indices != nil {
+			return evalIndexAssignment(node, val, env)
+		} else if node.Names != nil {
+			return evalUnpackAssignment(node.Names, val, env)
 		} else {
 			env.Set(node.Name.Value, val)
 		}
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return &object.BigInt{Value: node.Big}
+		}
 		return &object.Integer{Value: node.Value}
 	case *ast.FloatLiteral:
 		return &object.Float{Value: node.Value}
@@ -88,6 +104,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.RegexLiteral:
+		return compileRegex(node.Pattern, node.Flags)
+	case *ast.BytesLiteral:
+		return &object.Bytes{Value: node.Value}
 	case *ast.HashLiteral:
 		return evalHashLiteral(node, env)
 	case *ast.ArrayLiteral:
@@ -96,6 +116,14 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return elements[0]
 		}
 		return &object.Array{Elements: elements}
+	case *ast.SetLiteral:
+		return evalSetLiteral(node, env)
+	case *ast.TupleLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return &object.Tuple{Elements: elements}
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
@@ -106,20 +134,21 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return index
 		}
 		return evalIndexExpression(left, index)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
 		return evalPrefixExpression(node.Operator, right, env)
-	case *ast.InfixExpression:
-		if node.Operator == "." {
-			left := Eval(node.Left, env)
-			if isError(left) {
-				return left
-			}
-			return evalDotInfixExpression(left, node.Right, env)
+	case *ast.DotExpression:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
 		}
+		return evalDotExpression(left, node.Name.Value, env)
+	case *ast.InfixExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
@@ -140,6 +169,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.ClassStatement:
 		newEnv := object.NewEnclosedEnvironment(env)
 		newEnv.ShallowCopy(OBJECT.Env)
+		parents := []*object.Class{}
 		for _, value := range node.Parents {
 			pResult := Eval(value, env)
 			cls, ok := pResult.(*object.Class)
@@ -147,15 +177,60 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 				return &object.Error{Message: fmt.Sprintf("parent to be inherited from must be a class. got %T", pResult)}
 			}
 			newEnv.ShallowCopy(cls.Env)
+			parents = append(parents, cls)
 		}
 
 		// Let every statement in the block get its environment from outside the class,
 		// this hides instance variables and methods
 		evalClassBlockStatement(node.Body, newEnv)
 
-		class := &object.Class{Name: node.Name.String(), Env: newEnv}
+		class := &object.Class{Name: node.Name.String(), Env: newEnv, Parents: parents}
+		for _, value := range node.Interfaces {
+			iResult := Eval(value, env)
+			if isError(iResult) {
+				return iResult
+			}
+			iface, ok := iResult.(*object.Interface)
+			if !ok {
+				return newError("class %s can only implement interfaces. got %s", class.Name, iResult.Type())
+			}
+			if problems := missingMethods(class, iface); len(problems) > 0 {
+				return newError("class %s does not implement %s: %s", class.Name, iface.Name, strings.Join(problems, ", "))
+			}
+		}
 		env.Set(class.Name, class)
 		return class
+	case *ast.InterfaceStatement:
+		iface := &object.Interface{Name: node.Name.Value}
+		for _, method := range node.Methods {
+			iface.Methods = append(iface.Methods, object.InterfaceMethod{Name: method.Name.Value, Arity: len(method.Parameters)})
+		}
+		env.Set(iface.Name, iface)
+		return iface
+	case *ast.EnumStatement:
+		enum := &object.Enum{Name: node.Name.Value}
+		for ordinal, member := range node.Members {
+			if _, ok := enum.Member(member.Value); ok {
+				return newError("duplicate member %s in enum %s", member.Value, enum.Name)
+			}
+			enum.Members = append(enum.Members, &object.EnumMember{Enum: enum, Name: member.Value, Ordinal: int64(ordinal)})
+		}
+		env.Set(enum.Name, enum)
+		return enum
+	case *ast.RecordStatement:
+		record := &object.Record{Name: node.Name.Value}
+		for i, field := range node.Fields {
+			for _, previous := range node.Fields[:i] {
+				if previous.Value == field.Value {
+					return newError("duplicate field %s in record %s", field.Value, record.Name)
+				}
+			}
+			record.Fields = append(record.Fields, field.Value)
+		}
+		env.Set(record.Name, record)
+		return record
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
 	case *ast.CallExpression:
 		function := Eval(node.Function, env)
 		if isError(function) {
@@ -178,11 +253,17 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 }
 
 //evalImportStatement evaluates an import statement
+// builtin modules such as math are found before files on disk
 func evalImportStatement(Name string, alias ast.Expression, env *object.Environment) object.Object {
+	if module, ok := importBuiltinModule(Name); ok {
+		if alias != nil {
+			return &object.Module{Env: module.Env, Name: alias.(*ast.StringLiteral).String()}
+		}
+		return module
+	}
 	content, err := ioutil.ReadFile(Name + ".monkey")
 	if err != nil {
-		fmt.Printf("Could not open file : %s", Name)
-		panic(err)
+		return newError("could not import %s: no builtin module or file %s.monkey", Name, Name)
 	}
 	newEnv := object.NewEnvironment()
 	l := lexer.New(string(content))
@@ -278,7 +359,15 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 		evaluated := Eval(fn.Body, extendedEnv)
 		return unwrapReturnValue(evaluated)
 	case *object.Class:
-		cls := &object.ClassInstance{Name: fn.Name, Env: fn.Env}
+		// native classes build their instances in their constructor (see nativeClass)
+		if constructor, ok := fn.Env.Closed().Get("__New__"); ok {
+			if builtin, ok := constructor.(*object.Builtin); ok {
+				return builtin.Fn(args...)
+			}
+		}
+		// the instance gets its own store for instance variables which falls back
+		// to the class store (but not to the scope the class was defined in)
+		cls := &object.ClassInstance{Name: fn.Name, Env: object.NewEnclosedEnvironment(fn.Env.Closed()), Class: fn}
 		if value, ok := cls.Env.Get("__New__"); ok {
 			if value.Type() == object.FUNCTION_OBJ {
 				function, _ := value.(*object.Function)
@@ -286,6 +375,13 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 			}
 		}
 		return cls
+	case *object.BoundMethod:
+		return applyMethod(fn.Method, fn.Receiver, args)
+	case *object.Record:
+		if len(args) != len(fn.Fields) {
+			return newError("wrong number of arguments to record %s. got=%d, want=%d", fn.Name, len(args), len(fn.Fields))
+		}
+		return &object.RecordInstance{Record: fn, Values: args}
 	default:
 		return newError("not a function: %s", fn.Type())
 	}
@@ -314,19 +410,16 @@ func unwrapReturnValue(obj object.Object) object.Object {
 
 //evalIdentifer evaluates an identifier by getting its value from the env
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
-	//check whether the identifier is a builtin function first.
-	// this is done first so that the end user cannot overide builtin objects
-	// if this check is done second, then the user defined objects will have precedence
-	// over builtin types.
-	// this is a little deviation of my own from original monkey representation
-	// where user defined values have more precedence over builtin types
-	if builtin, ok := builtins[node.Value]; ok {
-		return builtin
-	}
+	// user defined values have precedence over builtins, as in the original
+	// monkey representation. With the number of builtins growing, checking
+	// builtins first would make names like `first` or `type` unusable as variables
 	if val, ok := env.Get(node.Value); ok {
 		return val
 	}
-	return newError("identifier not found: " + node.Value)
+	if builtin, ok := builtins[node.Value]; ok {
+		return builtin
+	}
+	return newError("identifier not found: %s", node.Value)
 }
 
 //nativeBoolToBooleanOjbect creates a boolean object
@@ -368,48 +461,284 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.TUPLE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(&object.Array{Elements: left.(*object.Tuple).Elements}, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
+	case left.Type() == object.BYTES_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalBytesIndexExpression(left.(*object.Bytes), index.(*object.Integer).Value)
 	default:
 		return newError("index operator not supported: %s", left.Type())
 	}
 }
 
+//evalSliceExpression evaluates slicing arrays, tuples, strings and bytes. eg. items[1:3]
+// missing bounds default to the ends, negative bounds count from the end and bounds
+// past the ends are clamped, so slicing never fails
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+	bounds := []object.Object{NULL, NULL}
+	for i, bound := range []ast.Expression{node.Start, node.End} {
+		if bound == nil {
+			continue
+		}
+		bounds[i] = Eval(bound, env)
+		if isError(bounds[i]) {
+			return bounds[i]
+		}
+		if bounds[i] != NULL && bounds[i].Type() != object.INTEGER_OBJ {
+			return newError("slice bounds must be INTEGER, got %s", bounds[i].Type())
+		}
+	}
+	switch left := left.(type) {
+	case *object.Array:
+		start, end := sliceBounds(bounds, len(left.Elements))
+		return &object.Array{Elements: append([]object.Object{}, left.Elements[start:end]...)}
+	case *object.Tuple:
+		start, end := sliceBounds(bounds, len(left.Elements))
+		return &object.Tuple{Elements: append([]object.Object{}, left.Elements[start:end]...)}
+	case *object.String:
+		runes := []rune(left.Value)
+		start, end := sliceBounds(bounds, len(runes))
+		return &object.String{Value: string(runes[start:end])}
+	case *object.Bytes:
+		start, end := sliceBounds(bounds, len(left.Value))
+		return &object.Bytes{Value: append([]byte{}, left.Value[start:end]...)}
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+}
+
+//sliceBounds resolves the start and end of a slice of a sequence of length elements
+func sliceBounds(bounds []object.Object, length int) (int, int) {
+	resolved := []int{0, length}
+	for i, bound := range bounds {
+		integer, ok := bound.(*object.Integer)
+		if !ok {
+			continue
+		}
+		index := integer.Value
+		if index < 0 {
+			index += int64(length)
+		}
+		if index < 0 {
+			index = 0
+		}
+		if index > int64(length) {
+			index = int64(length)
+		}
+		resolved[i] = int(index)
+	}
+	if resolved[1] < resolved[0] {
+		resolved[1] = resolved[0]
+	}
+	return resolved[0], resolved[1]
+}
+
+//evalUnpackAssignment binds each name to the matching element of a tuple or array
+// eg. let (q, r) = divmod(7, 2);
+func evalUnpackAssignment(names []*ast.Identifier, val object.Object, env *object.Environment) object.Object {
+	var elements []object.Object
+	switch val := val.(type) {
+	case *object.Tuple:
+		elements = val.Elements
+	case *object.Array:
+		elements = val.Elements
+	default:
+		return newError("cannot unpack %s", val.Type())
+	}
+	if len(elements) != len(names) {
+		return newError("cannot unpack %d values into %d names", len(elements), len(names))
+	}
+	for i, name := range names {
+		env.Set(name.Value, elements[i])
+	}
+	return nil
+}
+
+//evalSetLiteral evaluates the elements of a set literal into a Set
+func evalSetLiteral(node *ast.SetLiteral, env *object.Environment) object.Object {
+	set := object.NewSet()
+	for _, elementNode := range node.Elements {
+		element := Eval(elementNode, env)
+		if isError(element) {
+			return element
+		}
+		hashable, ok := object.AsHashKey(element)
+		if !ok {
+			return newError("unusable as set element: %s", element.Type())
+		}
+		set.Add(hashable)
+	}
+	return set
+}
+
+//evalInExpression evaluates membership tests. eg. 1 in {1, 2}
+// hashes are searched by key and strings by substring
+func evalInExpression(left, right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Set:
+		hashable, ok := object.AsHashKey(left)
+		return nativeBoolToBooleanObject(ok && right.Has(hashable))
+	case *object.Hash:
+		hashable, ok := object.AsHashKey(left)
+		if !ok {
+			return FALSE
+		}
+		_, found := right.Get(hashable)
+		return nativeBoolToBooleanObject(found)
+	case *object.Array:
+		return nativeBoolToBooleanObject(indexOfElement(right.Elements, left) >= 0)
+	case *object.Tuple:
+		return nativeBoolToBooleanObject(indexOfElement(right.Elements, left) >= 0)
+	case *object.String:
+		sub, ok := left.(*object.String)
+		if !ok {
+			return newError("type mismatch: %s in STRING", left.Type())
+		}
+		return nativeBoolToBooleanObject(strings.Contains(right.Value, sub.Value))
+	case *object.Bytes:
+		return evalBytesInExpression(left, right)
+	default:
+		return newError("unknown operator: %s in %s", left.Type(), right.Type())
+	}
+}
+
+//evalSetInfixExpression evaluates union (|), intersection (&) and difference (-) of sets
+func evalSetInfixExpression(operator string, left, right *object.Set) object.Object {
+	result := object.NewSet()
+	switch operator {
+	case "|":
+		for _, element := range append(left.Elements(), right.Elements()...) {
+			result.Add(element.(object.Hashable))
+		}
+	case "&":
+		for _, element := range left.Elements() {
+			if right.Has(element.(object.Hashable)) {
+				result.Add(element.(object.Hashable))
+			}
+		}
+	case "-":
+		for _, element := range left.Elements() {
+			if !right.Has(element.(object.Hashable)) {
+				result.Add(element.(object.Hashable))
+			}
+		}
+	case "==", "!=":
+		equal := left.Len() == right.Len() && evalSetInfixExpression("-", left, right).(*object.Set).Len() == 0
+		return nativeBoolToBooleanObject(equal == (operator == "=="))
+	default:
+		return newError("unknown operator: SET %s SET", operator)
+	}
+	return result
+}
+
+//evalTupleInfixExpression compares tuples element by element
+func evalTupleInfixExpression(operator string, left, right *object.Tuple) object.Object {
+	switch operator {
+	case "==":
+		return nativeBoolToBooleanObject(left.Equals(right))
+	case "!=":
+		return nativeBoolToBooleanObject(!left.Equals(right))
+	case "+":
+		elements := append(append([]object.Object{}, left.Elements...), right.Elements...)
+		return &object.Tuple{Elements: elements}
+	default:
+		return newError("unknown operator: TUPLE %s TUPLE", operator)
+	}
+}
+
+//evalIndexAssignment stores val at an index of an array or under a key of a hash
+// eg. let h["key"] = 1; let xs[0] = 2; let grid[y][x] = 3;
+func evalIndexAssignment(node *ast.LetStatement, val object.Object, env *object.Environment) object.Object {
+	container, ok := env.Get(node.Name.Value)
+	if !ok {
+		return newError("unknown identifier: %s", node.Name.Value)
+	}
+	// every index but the last selects the container to assign into. eg. m[i] in let m[i][j] = 0;
+	target := node.Name.Value
+	last := len(node.Indices) - 1
+	for _, indexNode := range node.Indices[:last] {
+		index := Eval(indexNode, env)
+		if isError(index) {
+			return index
+		}
+		switch container.(type) {
+		case *object.Hash, *object.Array:
+		default:
+			return newError("index assignment not supported: %s at %s", container.Type(), target)
+		}
+		if container = evalIndexExpression(container, index); isError(container) {
+			return container
+		}
+		target += "[" + indexNode.String() + "]"
+	}
+	index := Eval(node.Indices[last], env)
+	if isError(index) {
+		return index
+	}
+	switch container := container.(type) {
+	case *object.Hash:
+		key, ok := object.AsHashKey(index)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+		container.Set(key, val)
+	case *object.Array:
+		i, ok := index.(*object.Integer)
+		if !ok {
+			return newError("array index must be INTEGER, got %s", index.Type())
+		}
+		if i.Value < 0 || i.Value >= int64(len(container.Elements)) {
+			return newError("index %d out of range for array of length %d", i.Value, len(container.Elements))
+		}
+		container.Elements[i.Value] = val
+	default:
+		if last > 0 {
+			return newError("index assignment not supported: %s at %s", container.Type(), target)
+		}
+		return newError("index assignment not supported: %s", container.Type())
+	}
+	return nil
+}
+
 //evalHashLiteral evaluates and create a Hash object
 func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
-	for keyNode, valueNode := range node.Pairs {
+	hash := object.NewHash()
+	for _, keyNode := range node.Keys {
 		key := Eval(keyNode, env)
 		if isError(key) {
 			return key
 		}
-		hashKey, ok := key.(object.Hashable)
+		hashKey, ok := object.AsHashKey(key)
 		if !ok {
 			return newError("unusable as a hash key: %s", key.Type())
 		}
-		value := Eval(valueNode, env)
+		value := Eval(node.Pairs[keyNode], env)
 		if isError(value) {
 			return value
 		}
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: value}
+		hash.Set(hashKey, value)
 	}
-	return &object.Hash{Pairs: pairs}
+	return hash
 }
 
 //evalHashIndexExpression evaluates the results of indexing the Hash
 func evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObect := hash.(*object.Hash)
 
-	key, ok := index.(object.Hashable)
+	key, ok := object.AsHashKey(index)
 	if !ok {
 		return newError("unusable as hash key: %s", index.Type())
 	}
-	pair, ok := hashObect.Pairs[key.HashKey()]
+	value, ok := hashObect.Get(key)
 	if !ok {
 		return NULL
 	}
-	return pair.Value
+	return value
 }
 
 //evalArrayIndexExpression evaluate the result of indexing an array
@@ -447,53 +776,129 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 
 //evalMinusPrefixOperatorExpression evaluates negating a value
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+	switch right := right.(type) {
+	case *object.Integer:
+		if right.Value == math.MinInt64 {
+			return normalizeBigInt(new(big.Int).Neg(big.NewInt(right.Value)))
+		}
+		return &object.Integer{Value: -right.Value}
+	case *object.BigInt:
+		return normalizeBigInt(new(big.Int).Neg(right.Value))
+	case *object.Decimal:
+		return &object.Decimal{Unscaled: new(big.Int).Neg(right.Unscaled), Scale: right.Scale}
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	case *object.Duration:
+		return &object.Duration{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
 }
 
 //evalInfixExpression evaluates infix operations
 func evalInfixExpression(operator string, left, right object.Object) object.Object {
 	switch {
+	case operator == "in":
+		return evalInExpression(left, right)
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isInteger(left) && isInteger(right):
+		return evalBigIntInfixExpression(operator, toBigInt(left), toBigInt(right))
 	case left.Type() == object.FLOAT_OBJ && right.Type() == object.FLOAT_OBJ:
 		return evalFloatInfixExpression(operator, left, right)
-
-	case (left.Type() == object.FLOAT_OBJ && right.Type() == object.INTEGER_OBJ) || (right.Type() == object.FLOAT_OBJ && right.Type() == object.FLOAT_OBJ):
+	case (left.Type() == object.FLOAT_OBJ && isInteger(right)) || (isInteger(left) && right.Type() == object.FLOAT_OBJ):
 		return evalFloatIntegerInfixExpression(operator, left, right)
+	case (left.Type() == object.DECIMAL_OBJ || isInteger(left)) && (right.Type() == object.DECIMAL_OBJ || isInteger(right)):
+		return evalDecimalInfixExpression(operator, toDecimal(left), toDecimal(right))
+	case (left.Type() == object.DECIMAL_OBJ && right.Type() == object.FLOAT_OBJ) || (left.Type() == object.FLOAT_OBJ && right.Type() == object.DECIMAL_OBJ):
+		return evalDecimalFloatInfixExpression(operator, left, right)
+	case left.Type() == object.RECORDINSTANCE_OBJ && right.Type() == object.RECORDINSTANCE_OBJ:
+		return evalRecordInfixExpression(operator, left, right)
+	case left.Type() == object.SET_OBJ && right.Type() == object.SET_OBJ:
+		return evalSetInfixExpression(operator, left.(*object.Set), right.(*object.Set))
+	case left.Type() == object.TUPLE_OBJ && right.Type() == object.TUPLE_OBJ:
+		return evalTupleInfixExpression(operator, left.(*object.Tuple), right.(*object.Tuple))
+	case left.Type() == object.BYTES_OBJ && right.Type() == object.BYTES_OBJ:
+		return evalBytesInfixExpression(operator, left.(*object.Bytes), right.(*object.Bytes))
+	case isTimeObject(left) || isTimeObject(right):
+		return evalTimeInfixExpression(operator, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left, right)
 	case operator == "==":
 		return nativeBoolToBooleanObject(left == right)
 	case operator == "!=":
 		return nativeBoolToBooleanObject(left != right)
 	case left.Type() != right.Type():
 		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
-	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
-		return evalStringInfixExpression(operator, left, right)
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
-//evalDotInfixOperation evaluates a method
-func evalDotInfixExpression(left object.Object, right ast.Node, env *object.Environment) object.Object {
-	switch left.(type) {
-	case *object.ClassInstance:
-		left, ok := left.(*object.ClassInstance)
-		if !ok {
-			return nil
+//evalMatchExpression evaluates the body of the first arm whose pattern equals the subject.
+// matches on an enum member without a `_` arm must name every member of the enum
+func evalMatchExpression(me *ast.MatchExpression, env *object.Environment) object.Object {
+	subject := Eval(me.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+	patterns := make([]object.Object, len(me.Arms))
+	wildcard := false
+	for i, arm := range me.Arms {
+		if arm.Pattern == nil {
+			wildcard = true
+			continue
 		}
-		return evalClassDotOperation(left, right, env)
-	case *object.Module:
-		left, ok := left.(*object.Module)
-		if !ok {
-			return nil
+		patterns[i] = Eval(arm.Pattern, env)
+		if isError(patterns[i]) {
+			return patterns[i]
 		}
-		return evalModuleDotOperation(left, right, env)
+	}
+	if member, ok := subject.(*object.EnumMember); ok && !wildcard {
+		missing := []string{}
+		for _, m := range member.Enum.Members {
+			covered := false
+			for _, pattern := range patterns {
+				if pattern == m {
+					covered = true
+				}
+			}
+			if !covered {
+				missing = append(missing, m.Name)
+			}
+		}
+		if len(missing) > 0 {
+			return newError("non-exhaustive match on enum %s: missing %s", member.Enum.Name, strings.Join(missing, ", "))
+		}
+	}
+	for i, arm := range me.Arms {
+		if arm.Pattern == nil || objectsEqual(subject, patterns[i]) {
+			return Eval(arm.Body, env)
+		}
+	}
+	return NULL
+}
+
+//objectsEqual compares two objects with the semantics of the == operator
+func objectsEqual(left, right object.Object) bool {
+	return evalInfixExpression("==", left, right) == TRUE
+}
+
+//evalRecordInfixExpression compares two records field by field
+func evalRecordInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.RecordInstance)
+	rightVal := right.(*object.RecordInstance)
+	equal := leftVal.Record == rightVal.Record
+	for i := 0; equal && i < len(leftVal.Values); i++ {
+		equal = objectsEqual(leftVal.Values[i], rightVal.Values[i])
+	}
+	switch operator {
+	case "==":
+		return nativeBoolToBooleanObject(equal)
+	case "!=":
+		return nativeBoolToBooleanObject(!equal)
 	default:
-		return &object.Error{Message: fmt.Sprintf("Dot operation not supported for %T", left.Type())}
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -532,57 +937,113 @@ func evalClassBlockStatement(block *ast.BlockStatement, env *object.Environment)
 	return result
 }
 
-//evalModuleDotOperator evaluates dot operation between and object
-func evalModuleDotOperation(left *object.Module, right ast.Node, env *object.Environment) object.Object {
-	switch right.(type) {
-	case *ast.CallExpression:
-		right, ok := right.(*ast.CallExpression)
+//missingMethods lists the methods of an interface a class does not provide,
+// or provides with the wrong number of parameters
+func missingMethods(class *object.Class, iface *object.Interface) []string {
+	problems := []string{}
+	for _, method := range iface.Methods {
+		value, ok := class.Env.Closed().Get(method.Name)
 		if !ok {
-			return nil
-		}
-		function := Eval(right.Function, left.Env.Closed())
-		if isError(function) {
-			return function
+			problems = append(problems, fmt.Sprintf("missing method %s", method.Name))
+			continue
 		}
-		args := evalExpressions(right.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
+		switch fn := value.(type) {
+		case *object.Function:
+			if len(fn.Parameters) != method.Arity {
+				problems = append(problems, fmt.Sprintf("method %s takes %d arguments, want %d", method.Name, len(fn.Parameters), method.Arity))
+			}
+		case *object.Builtin:
+		default:
+			problems = append(problems, fmt.Sprintf("%s is not a method", method.Name))
 		}
-		return applyFunction(function, args)
-
-	case *ast.Identifier:
-		return Eval(right, left.Env.Closed())
-	default:
-		return &object.Error{Message: "Cannot perform Dot operation"}
 	}
-
+	return problems
 }
 
-//evalClassDotOperator evaluates dot operation between and object
-func evalClassDotOperation(left *object.ClassInstance, right ast.Node, env *object.Environment) object.Object {
-	switch right.(type) {
-	case *ast.CallExpression:
-		right, ok := right.(*ast.CallExpression)
-		if !ok {
-			return nil
+//evalDotExpression evaluates attribute access on an object.
+// methods found on a class or on a builtin type are returned bound to left
+func evalDotExpression(left object.Object, name string, env *object.Environment) object.Object {
+	switch left := left.(type) {
+	case *object.ClassInstance:
+		if isPrivate(name) && !isSelf(left, env) {
+			return newError("cannot access private member %s of %s", name, left.Name)
 		}
-		function := Eval(right.Function, left.Env.Closed())
-		if isError(function) {
-			return function
+		if value, ok := left.Env.Closed().Get(name); ok {
+			return value
 		}
-		args := evalExpressions(right.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
+		if value, ok := left.Env.Get(name); ok {
+			switch value.(type) {
+			case *object.Function, *object.Builtin:
+				return &object.BoundMethod{Name: name, Receiver: left, Method: value}
+			}
+			return value
+		}
+		return newError("%s has no attribute %s", left.Inspect(), name)
+	case *object.Class:
+		if isPrivate(name) {
+			return newError("cannot access private member %s of %s", name, left.Name)
+		}
+		if value, ok := left.Env.Closed().Get(name); ok {
+			return value
+		}
+		return newError("%s has no attribute %s", left.Inspect(), name)
+	case *object.Module:
+		if isPrivate(name) {
+			return newError("cannot access private name %s of module %s", name, left.Name)
+		}
+		if value, ok := left.Env.Closed().Get(name); ok {
+			return value
+		}
+		return newError("module %s has no name %s", left.Name, name)
+	case *object.Enum:
+		if member, ok := left.Member(name); ok {
+			return member
+		}
+	case *object.EnumMember:
+		switch name {
+		case "name":
+			return &object.String{Value: left.Name}
+		case "ordinal":
+			return &object.Integer{Value: left.Ordinal}
+		}
+	case *object.RecordInstance:
+		if value, ok := left.Get(name); ok {
+			return value
 		}
-		return applyMethod(function, left, args)
-	case *ast.Identifier:
-		return Eval(right, left.Env.Closed())
-	default:
-		return &object.Error{Message: "Cannot perform Dot operation"}
 	}
+	if method, ok := builtinMethods[left.Type()][name]; ok {
+		return &object.BoundMethod{Name: name, Receiver: left, Method: method}
+	}
+	switch left := left.(type) {
+	case *object.Enum:
+		return newError("enum %s has no member %s", left.Name, name)
+	case *object.RecordInstance:
+		return newError("record %s has no field %s", left.Record.Name, name)
+	}
+	return newError("%s has no attribute %s", left.Inspect(), name)
 }
 
-//applyMethod runs a function call
+//isPrivate checks whether a member name is private.
+// names starting with an underscore are private, except for __special__ names
+// such as __str__ and __New__
+func isPrivate(name string) bool {
+	if !strings.HasPrefix(name, "_") {
+		return false
+	}
+	return !(len(name) > 4 && strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__"))
+}
+
+//isSelf checks whether obj is the receiver of the method being evaluated in env.
+// private members are only reachable through self. the receiver is recorded by
+// applyMethod rather than looked up by name, so binding another object to self does not
+// open it up
+func isSelf(obj object.Object, env *object.Environment) bool {
+	receiver, ok := env.Receiver()
+	return ok && receiver == obj
+}
+
+//applyMethod runs a method call with left as the receiver.
+// functions see the receiver as self, builtins get it as their first argument
 func applyMethod(fn object.Object, left object.Object, args []object.Object) object.Object {
 	switch fn := fn.(type) {
 	//check whether the function is a builtin function first.
@@ -592,13 +1053,14 @@ func applyMethod(fn object.Object, left object.Object, args []object.Object) obj
 	// this is a little deviation of my own from original monkey representation
 	// where user defined values have more precedence over builtin types
 	case *object.Builtin:
-		return fn.Fn(left)
+		return fn.Fn(append([]object.Object{left}, args...)...)
 
 	case *object.Function:
 		extendedEnv := extendFunctionEnv(fn, args)
 		class, ok := left.(*object.ClassInstance)
 		if ok {
 			extendedEnv.Set("self", class)
+			extendedEnv.SetReceiver(class)
 		}
 		evaluated := Eval(fn.Body, extendedEnv)
 		return unwrapReturnValue(evaluated)
@@ -613,13 +1075,20 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 	switch operator {
-	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
-	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
-	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+	case "+", "-", "*":
+		// results that overflow an int64 are promoted to a BigInt
+		if result, ok := int64Arithmetic(operator, leftVal, rightVal); ok {
+			return &object.Integer{Value: result}
+		}
+		return evalBigIntInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
 	case "/":
+		// exact divisions stay integers, everything else becomes a float
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return evalBigIntInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
+		}
+		if rightVal != 0 && leftVal%rightVal == 0 {
+			return &object.Integer{Value: leftVal / rightVal}
+		}
 		return &object.Float{Value: float64(leftVal) / float64(rightVal)}
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
@@ -662,18 +1131,8 @@ func evalFloatInfixExpression(operator string, left, right object.Object) object
 
 //evalFloatInfixExpression evaluates infix expressions where both operands are floats
 func evalFloatIntegerInfixExpression(operator string, left, right object.Object) object.Object {
-	var leftVal float64
-	var rightVal float64
-	if left.Type() == object.INTEGER_OBJ {
-		leftVal = float64(left.(*object.Integer).Value)
-	} else {
-		leftVal = left.(*object.Float).Value
-	}
-	if right.Type() == object.INTEGER_OBJ {
-		rightVal = float64(right.(*object.Integer).Value)
-	} else {
-		rightVal = right.(*object.Float).Value
-	}
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
 	switch operator {
 	case "+":
 		return &object.Float{Value: leftVal + rightVal}
@@ -696,13 +1155,24 @@ func evalFloatIntegerInfixExpression(operator string, left, right object.Object)
 	}
 }
 
-//evalStringInfixExpression evaluates infix operations involving strings
+//evalStringInfixExpression evaluates infix operations involving strings.
+// strings compare by value, both for equality and for ordering (byte-wise, so
+// sort and comparators order them as Go does), unlike arrays and hashes which
+// are equal only when they are the same object
 func evalStringInfixExpression(operator string, left object.Object, right object.Object) object.Object {
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
 	switch operator {
 	case "+":
 		return &object.String{Value: leftVal + rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
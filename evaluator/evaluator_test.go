@@ -1,10 +1,22 @@
 package evaluator
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parser"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestEvalIntegerExpression(t *testing.T) {
@@ -304,6 +316,1421 @@ ourFunction(20) + first + second;`
 	testIntegerObject(t, testEval(input), 70)
 }
 
+func TestTypeBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"type(1) == type(2)", true},
+		{"type(1) == type(1.5)", false},
+		{`type("a") == type("b")`, true},
+		{"class A() {}; type(A()) == A", true},
+		{"isinstance(1, type(2))", true},
+		{`isinstance("a", type(2))`, false},
+		{"class A() {}; class B(A) {}; isinstance(B(), A)", true},
+		{"class A() {}; class B(A) {}; isinstance(A(), B)", false},
+		{"class A() {}; class B() {}; class C(A, B) {}; isinstance(C(), B)", true},
+		{"callable(fn() {})", true},
+		{"callable(len)", true},
+		{"class A() {}; callable(A)", true},
+		{"callable(1)", false},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestAttributeBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`class A() { let x = 1 }; getattr(A(), "x")`, 1},
+		{`class A() { let x = 1 }; getattr(A, "x")`, 1},
+		{`class A() {}; getattr(A(), "y", 5)`, 5},
+		{`class A() { let x = 1 }; let a = A(); setattr(a, "x", 2); a.x`, 2},
+		{`class A() { let x = 1 }; let a = A(); let b = A(); setattr(a, "x", 2); b.x`, 1},
+		{`class A() { let x = 1 }; let a = A(); setattr(a, "x", 2); delattr(a, "x"); a.x`, 1},
+		{`class A() { let x = 1 }; hasattr(A(), "x")`, true},
+		{`class A() { let x = 1 }; hasattr(A(), "y")`, false},
+		{`class A() {}; let a = A(); setattr(a, "y", 3); hasattr(a, "y")`, true},
+		{`class A() { let x = 1 }; delattr(A(), "x")`, "<Instance of Class A> has no attribute x"},
+		{`getattr(1, "x")`, "first argument to `getattr` must be CLASS_INSTANCE, CLASS or MODULE, got INTEGER"},
+		{`class A() { let x = 1 }; let a = A(); setattr(a, "y", 2); len(dir(a))`, 4},
+		{`class A() { let x = 1 }; let a = A(); setattr(a, "y", 2); vars(a)["y"]`, 2},
+		{`class A() { let x = 1 }; vars(A())["x"]`, nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestDirBuiltin(t *testing.T) {
+	input := `class A() { let x = 1; let go = fn() {} }; dir(A())`
+
+	evaluated := testEval(input)
+	array, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+	expected := []string{"__repr__", "__str__", "go", "x"}
+	if len(array.Elements) != len(expected) {
+		t.Fatalf("wrong number of members. got=%d, want=%d", len(array.Elements), len(expected))
+	}
+	for i, name := range expected {
+		if array.Elements[i].Inspect() != name {
+			t.Errorf("member %d wrong. got=%q, want=%q", i, array.Elements[i].Inspect(), name)
+		}
+	}
+}
+
+func TestInterfaces(t *testing.T) {
+	shape := `interface Shape { fn area(); fn scale(factor); }; `
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{shape + `class Square() implements Shape { let area = fn() { 4 }; let scale = fn(f) { f } }; Square().area()`, 4},
+		{shape + `class Base() { let area = fn() { 1 } }; class Square(Base) implements Shape { let scale = fn(f) { f } }; Square().area()`, 1},
+		{shape + `class Square() implements Shape { let scale = fn(f) { f } }`, "class Square does not implement Shape: missing method area"},
+		{shape + `class Square() implements Shape { let area = fn(x) { 4 }; let scale = fn() { 1 } }`, "class Square does not implement Shape: method area takes 1 arguments, want 0, method scale takes 0 arguments, want 1"},
+		{`class Square() implements Square {}`, "identifier not found: Square"},
+		{`let Shape = 1; class Square() implements Shape {}`, "class Square can only implement interfaces. got INTEGER"},
+		{shape + `class Square() { let area = fn() { 4 }; let scale = fn(f) { f } }; implements(Square(), Shape)`, true},
+		{shape + `class Square() { let area = fn() { 4 } }; implements(Square(), Shape)`, false},
+		{shape + `class Square() { let area = fn() { 4 }; let scale = fn(f) { f } }; implements(Square, Shape)`, true},
+		{shape + `implements(1, Shape)`, false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEnums(t *testing.T) {
+	color := `enum Color { Red, Green, Blue }; `
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{color + `Color.Green.ordinal`, 1},
+		{color + `Color.Blue.name`, "Blue"},
+		{color + `str(Color.Red)`, "Color.Red"},
+		{color + `Color.Red == Color.Red`, true},
+		{color + `Color.Red == Color.Blue`, false},
+		{color + `len(Color.values())`, 3},
+		{color + `let members = Color.values(); members[2] == Color.Blue`, true},
+		{color + `isinstance(Color.Red, Color)`, true},
+		{color + `type(Color.Red) == Color`, true},
+		{color + `{Color.Red: 1, Color.Blue: 2}[Color.Blue]`, 2},
+		{color + `Color.Purple`, errorMessage("enum Color has no member Purple")},
+		{`enum Color { Red, Red }`, errorMessage("duplicate member Red in enum Color")},
+		{color + `match (Color.Green) { Color.Red => 1, Color.Green => 2, Color.Blue => 3 }`, 2},
+		{color + `match (Color.Blue) { Color.Red => 1, _ => 9 }`, 9},
+		{color + `match (Color.Blue) { Color.Red => 1, Color.Green => 2 }`, errorMessage("non-exhaustive match on enum Color: missing Blue")},
+		{`match (5) { 1 => 1, 5 => { let a = 2; a * 5 } }`, 10},
+		{`match ("b") { "a" => 1 }`, nil},
+		{`let f = fn(x) { match (x) { 1 => { return 10 } }; 20 }; f(1)`, 10},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	point := `record Point(x, y); `
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{point + `Point(1, 2).y`, 2},
+		{point + `str(Point(1, 2))`, "Point(x=1, y=2)"},
+		{point + `Point(1, 2).__str__()`, "Point(x=1, y=2)"},
+		{point + `Point(1, 2) == Point(1, 2)`, true},
+		{point + `Point(1, 2) != Point(1, 3)`, true},
+		{point + `record Other(x, y); Point(1, 2) == Other(1, 2)`, false},
+		{point + `let p = Point(1, 2); let q = p.with({"x": 5}); p.x + q.x`, 6},
+		{point + `{Point(1, 2): "a"}[Point(1, 2)]`, "a"},
+		{point + `isinstance(Point(1, 2), Point)`, true},
+		{point + `type(Point(1, 2)) == Point`, true},
+		{point + `Point(1)`, errorMessage("wrong number of arguments to record Point. got=1, want=2")},
+		{point + `Point(1, 2).z`, errorMessage("record Point has no field z")},
+		{point + `Point(1, 2).with({"z": 1})`, errorMessage("record Point has no field z")},
+		{point + `let p = Point(1, 2); let p.x = 3`, errorMessage("record Point is immutable, use with() to change x")},
+		{`record P(x, y, x)`, errorMessage("duplicate field x in record P")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestPrivateMembers(t *testing.T) {
+	account := `class Account() {
+	let _balance = 0;
+	let _log = fn(x) { x };
+	let deposit = fn(x) { let self._balance = self._balance + self._log(x); self._balance };
+	let peek = fn(other) { other._balance };
+}; let a = Account(); `
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{account + `a.deposit(5); a.deposit(7)`, 12},
+		{account + `a._balance`, errorMessage("cannot access private member _balance of Account")},
+		{account + `a._log(1)`, errorMessage("cannot access private member _log of Account")},
+		{account + `let a._balance = 100`, errorMessage("cannot access private member _balance of Account")},
+		{account + `a.peek(Account())`, errorMessage("cannot access private member _balance of Account")},
+		{account + `a.__str__()`, "<Instance of Class Account>"},
+		{account + `getattr(a, "_balance")`, errorMessage("cannot access private member _balance of <Instance of Class Account>")},
+		{account + `hasattr(a, "_balance")`, false},
+		{account + `len(dir(a))`, 4},
+		{`class A() { let _x = 3; let get = fn() { let f = fn() { self._x }; f() }; }; A().get()`, 3},
+		{account + `let self = a; self._balance`, errorMessage("cannot access private member _balance of Account")},
+		{account + `let self = a; let self._balance = 100`, errorMessage("cannot access private member _balance of Account")},
+		{account + `let f = fn(self) { self._balance }; f(a)`, errorMessage("cannot access private member _balance of Account")},
+		{account + `let g = fn() { let self = a; a._balance }; g()`, errorMessage("cannot access private member _balance of Account")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestPrivateModuleNames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "monkey")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	module := filepath.Join(dir, "lib")
+	source := `let _secret = 42; let reveal = fn() { _secret };`
+	if err := ioutil.WriteFile(module+".monkey", []byte(source), 0644); err != nil {
+		t.Fatalf("could not write module: %s", err)
+	}
+
+	imports := `import "` + module + `" as "lib"; `
+	testIntegerObject(t, testEval(imports+`lib.reveal()`), 42)
+	testErrorObject(t, testEval(imports+`lib._secret`), "cannot access private name _secret of module lib")
+}
+
+func TestFSModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "monkey")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	missing := filepath.Join(dir, "missing.txt")
+
+	setup := `import "fs"; let dir = "` + dir + `"; let path = dir + "/notes.txt"; `
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{setup + `fs.writeFile(path, "one"); fs.appendFile(path, " two"); fs.readFile(path)`, "one two"},
+		{setup + `fs.writeFile(path, "abc"); fs.exists(path)`, true},
+		{setup + `fs.exists(dir + "/nothing")`, false},
+		{setup + `fs.mkdir(dir + "/a/b"); fs.writeFile(dir + "/a/b/c.txt", ""); str(fs.listDir(dir + "/a"))`, "[b]"},
+		{setup + `fs.mkdir(dir + "/d"); fs.remove(dir + "/d"); fs.exists(dir + "/d")`, false},
+		{setup + `fs.mkdir(dir + "/e/f"); fs.remove(dir + "/e", true); fs.exists(dir + "/e")`, false},
+		{setup + `fs.writeFile(path, "12345"); let s = fs.stat(path); str([s["name"], s["size"], s["isDir"]])`, "[notes.txt, 5, false]"},
+		{setup + `fs.stat(dir)["isDir"]`, true},
+		{setup + `let f = fs.open(path, "w"); f.write("first
+second
+"); f.write("third"); f.close(); let f = fs.open(path); str([f.readLine(), f.readLine(), f.readLine(), f.readLine()])`, "[first, second, third, null]"},
+		{setup + `let f = fs.open(path, "a"); f.close(); f.write("x")`, errorMessage("write: file " + filepath.Join(dir, "notes.txt") + " is closed")},
+		{setup + `fs.open(path, "x")`, errorMessage(`unknown file mode "x", want one of r, w or a`)},
+		{setup + `let f = fs.open(path, "w"); f.close(); str([isinstance(f, fs.File), type(f)])`, "[true, class File]"},
+		{`import "fs"; fs.File()`, errorMessage("File objects cannot be created directly")},
+		{`import "fs"; fs.readFile("` + missing + `")`, errorMessage("readFile: open " + missing + ": no such file or directory")},
+		{`import "fs"; let (text, err) = try(fs.readFile, "` + missing + `"); str([text, err])`, "[null, readFile: open " + missing + ": no such file or directory]"},
+		{`let (value, err) = try(fn(x) { x * 2 }, 21); str([value, err])`, "[42, null]"},
+		{`try()`, errorMessage("wrong number of arguments. got=0, want=1 or more")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestInputAndOSModule(t *testing.T) {
+	defer func(in *bufio.Reader, out io.Writer, exitFn func(int)) {
+		stdin, stdout, exit = in, out, exitFn
+		SetArgs([]string{})
+	}(stdin, stdout, exit)
+
+	var prompts bytes.Buffer
+	stdout = &prompts
+	stdin = bufio.NewReader(strings.NewReader("ann\r\nbob\nlast"))
+	testStringObject(t, testEval(`input("name? ")`), "ann")
+	testStringObject(t, testEval(`readLine()`), "bob")
+	testStringObject(t, testEval(`input()`), "last")
+	testNullObject(t, testEval(`readLine()`))
+	if prompts.String() != "name? " {
+		t.Errorf("wrong prompt output. got=%q", prompts.String())
+	}
+
+	exitCode := -1
+	exit = func(code int) { exitCode = code }
+	SetArgs([]string{"script.monkey", "--verbose", "x"})
+	os.Setenv("MONKEY_TEST_VAR", "set")
+	defer os.Unsetenv("MONKEY_TEST_VAR")
+	cwd, _ := os.Getwd()
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "os"; str(os.args)`, "[script.monkey, --verbose, x]"},
+		{`import "os"; os.env("MONKEY_TEST_VAR")`, "set"},
+		{`import "os"; os.env("MONKEY_TEST_UNSET")`, nil},
+		{`import "os"; os.env("MONKEY_TEST_UNSET", "default")`, "default"},
+		{`import "os"; os.setEnv("MONKEY_TEST_VAR", "changed"); os.env("MONKEY_TEST_VAR")`, "changed"},
+		{`import "os"; os.cwd()`, cwd},
+		{`import "os"; os.exit(3)`, nil},
+		{`import "os"; os.exit("3")`, errorMessage("argument 1 to `exit` must be INTEGER, got STRING")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+	if exitCode != 3 {
+		t.Errorf("os.exit did not exit with 3. got=%d", exitCode)
+	}
+}
+
+func TestExec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "monkey")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	dir, _ = filepath.EvalSymlinks(dir)
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "os"; let r = os.exec("sh", ["-c", "echo out; echo err 1>&2; exit 3"]); str([r.stdout, r.stderr, r.exitCode])`, "[out\n, err\n, 3]"},
+		{`import "os"; os.exec("cat", [], {"stdin": "piped"}).stdout`, "piped"},
+		{`import "os"; os.exec("sh", ["-c", "echo $MONKEY_EXEC_VAR"], {"env": {"MONKEY_EXEC_VAR": "set"}}).stdout`, "set\n"},
+		{`import "os"; os.exec("pwd", [], {"cwd": "` + dir + `"}).stdout`, dir + "\n"},
+		{`import "os"; os.exec("true").exitCode`, 0},
+		{`import "os"; os.exec("sleep", ["5"], {"timeout": 50})`, errorMessage("sleep timed out after 50ms")},
+		{`import "os"; os.exec("monkey-no-such-program")`, errorMessage("could not run monkey-no-such-program: executable file not found in $PATH")},
+		{`import "os"; os.exec("true", [], {"shell": true})`, errorMessage("unknown or invalid `exec` option shell: true")},
+		{`import "os"; os.exec("echo", [1])`, errorMessage("arguments of `exec` must be STRING, got INTEGER")},
+		{`import "os"; let p = os.spawn("cat"); p.write("one
+two
+"); let first = p.readLine(); p.closeStdin(); let r = p.wait(); str([first, r.stdout, r.exitCode])`, "[one, two\n, 0]"},
+		{`import "os"; let p = os.spawn("sh", ["-c", "echo a 1>&2; echo b"]); str([p.readErrLine(), p.readLine(), p.readLine()])`, "[a, b, null]"},
+		{`import "os"; let p = os.spawn("sleep", ["5"]); p.kill(); p.wait().exitCode`, -1},
+		{`import "os"; let p = os.spawn("sleep", ["5"]); p.kill(); p.kill(); str([isinstance(p, os.Process), p.wait().exitCode])`, "[true, -1]"},
+		{`import "os"; os.Process()`, errorMessage("Process objects cannot be created directly")},
+		{`import "os"; let p = os.spawn("cat", [], {"stdin": ""}); p.write("x")`, errorMessage("write: standard input of cat is closed")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+
+	// a child left running in the background holds the pipes open, which must not delay the timeout
+	background := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "os"; os.exec("sh", ["-c", "sleep 5 & sleep 5"], {"timeout": 100})`, errorMessage("sh timed out after 100ms")},
+		{`import "os"; os.spawn("sh", ["-c", "sleep 5 & sleep 5"], {"timeout": 100}).wait()`, errorMessage("sh timed out after 100ms")},
+		{`import "os"; let p = os.spawn("sh", ["-c", "sleep 5 & sleep 5"]); p.kill(); p.wait().exitCode`, -1},
+	}
+	for _, tt := range background {
+		start := time.Now()
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("%s took %s", tt.input, elapsed)
+		}
+	}
+
+	Sandbox = true
+	defer func() { Sandbox = false }()
+	testErrorObject(t, testEval(`import "os"; os.exec("true")`), "exec: running programs is disabled in sandboxed interpreters")
+	testErrorObject(t, testEval(`import "os"; os.spawn("true")`), "spawn: running programs is disabled in sandboxed interpreters")
+}
+
+func TestMethodValues(t *testing.T) {
+	counter := `class Counter() {
+	let count = 1;
+	let get = fn() { self.count };
+	let add = fn(n) { let self.count = self.count + n; self };
+}; `
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{counter + `let get = Counter().get; get()`, 1},
+		{counter + `let c = Counter(); let get = c.get; c.add(4); get()`, 5},
+		{counter + `let make = fn() { Counter() }; make().add(2).get()`, 3},
+		{counter + `let c = Counter(); c.add(1).add(2).add(3).count`, 7},
+		{counter + `class Holder() { let inner = null }; let h = Holder(); let h.inner = Counter(); h.inner.add(9).get()`, 10},
+		{counter + `let c = Counter(); callable(c.get)`, true},
+		{counter + `let get = Counter.get; callable(get)`, true},
+		{counter + `Counter().missing`, errorMessage("<Instance of Class Counter> has no attribute missing")},
+		{`"abc".upper()`, "ABC"},
+		{`let s = "ABC"; s.lower()`, "abc"},
+		{`[1, 2].map(fn(x) { x * 2 })[1]`, 4},
+		{`"abc".missing()`, errorMessage("abc has no attribute missing")},
+		{`enum Color { Red, Green }; Color.values()[1] == Color.Green`, true},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestStringMethods(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len("héllo")`, 5},
+		{`len(split("a,b,c", ","))`, 3},
+		{`"a,b,c".split(",")[2]`, "c"},
+		{`"  a  b ".split()[1]`, "b"},
+		{`join(["a", "b"], "-")`, "a-b"},
+		{`", ".join([1, "two"])`, "1, two"},
+		{`trim("  hi  ")`, "hi"},
+		{`"xxhixx".trim("x")`, "hi"},
+		{`upper("héllo")`, "HÉLLO"},
+		{`"ÀB".lower()`, "àb"},
+		{`replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`"a-b-c".replace("-", "+", 1)`, "a+b-c"},
+		{`startsWith("monkey", "mon")`, true},
+		{`"monkey".endsWith("mon")`, false},
+		{`"monkey".contains("key")`, true},
+		{`contains("monkey", "ape")`, false},
+		{`"héllo".indexOf("l")`, 2},
+		{`indexOf("abc", "z")`, -1},
+		{`"ab".repeat(3)`, "ababab"},
+		{`repeat("ab", -1)`, errorMessage("argument to `repeat` must not be negative, got -1")},
+		{`"a".repeat(4611686018427387904)`, errorMessage("result of `repeat` would be longer than 268435456 bytes")},
+		{`"ab".repeat(134217729)`, errorMessage("result of `repeat` would be longer than 268435456 bytes")},
+		{`len("".repeat(4611686018427387904))`, 0},
+		{`padLeft("a", 100000000000)`, errorMessage("width of `padLeft` must be at most 268435456")},
+		{`"a".padRight(268435457, "xy")`, errorMessage("width of `padRight` must be at most 268435456")},
+		{`"7".padLeft(3, "0")`, "007"},
+		{`padLeft("é", 3)`, "  é"},
+		{`"ab".padRight(5, "xy")`, "abxyx"},
+		{`"abcdef".padRight(2)`, "abcdef"},
+		{`len("héllo".chars())`, 5},
+		{`chars("日本")[1]`, "本"},
+		{`"{} + {} = {}".format(1, 2, 3)`, "1 + 2 = 3"},
+		{`format("{1}{0}{{}}", "a", "b")`, "ba{}"},
+		{`"{}".format()`, errorMessage("format string needs argument 0, got 0 arguments")},
+		{`"{".format(1)`, errorMessage("unclosed placeholder in format string \"{\"")},
+		{`split(1, ",")`, errorMessage("argument 1 to `split` must be STRING, got INTEGER")},
+		{`"abc".upper(1)`, errorMessage("wrong number of arguments. got=2, want=1")},
+		{`let split = fn(s) { "mine" }; split("a,b")`, "mine"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestStringComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"a" == "a"`, true},
+		{`let a = "ab"; let b = "a" + "b"; a == b`, true},
+		{`"a" != "a"`, false},
+		{`"a" != "b"`, true},
+		{`"a" < "b"`, true},
+		{`"b" > "a"`, true},
+		{`"B" < "a"`, true},
+		{`"a" == 1`, false},
+		{`"a" < 1`, errorMessage("type mismatch: STRING < INTEGER")},
+		{`"a" - "b"`, errorMessage("unknown operator: STRING - STRING")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestArrayMethods(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`map([1, 2, 3], fn(x) { x * 2 })[2]`, 6},
+		{`len([1, 2, 3, 4].filter(fn(x) { x > 2 }))`, 2},
+		{`[1, 2, 3, 4].reduce(fn(acc, x) { acc + x })`, 10},
+		{`reduce([], fn(acc, x) { acc + x }, 5)`, 5},
+		{`reduce([], fn(acc, x) { acc + x })`, errorMessage("reduce of empty array with no initial value")},
+		{`[1, 5, 7].find(fn(x) { x > 4 })`, 5},
+		{`[1, 5, 7].find(fn(x) { x > 9 })`, nil},
+		{`[1, 5, 7].any(fn(x) { x > 6 })`, true},
+		{`all([1, 5, 7], fn(x) { x > 1 })`, false},
+		{`all([1, true, "a"])`, true},
+		{`any([])`, false},
+		{`[3, 1, 2].sort()[0]`, 1},
+		{`["b", "c", "a"].sort()[2]`, "c"},
+		{`[3, 1, 2].sort(fn(a, b) { a > b })[0]`, 3},
+		{`[3, 1, 2].sort(fn(a, b) { a - b })[2]`, 3},
+		{`let xs = [3, 1, 2]; xs.sort(); xs[0]`, 3},
+		{`[1, "a"].sort()`, errorMessage("type mismatch: STRING < INTEGER")},
+		{`[1, 2, 3].reverse()[0]`, 3},
+		{`zip([1, 2, 3], ["a", "b"])[1][1]`, "b"},
+		{`len(zip([1, 2, 3], ["a", "b"]))`, 2},
+		{`["a", "b"].enumerate()[1][0]`, 1},
+		{`len([[1, 2], [3, [4, 5]]].flatten())`, 4},
+		{`len(flatten([[1, 2], [3, [4, 5]]], 2))`, 5},
+		{`len([1, 2, 1, "a", "a"].unique())`, 3},
+		{`str([1, 1.0, 2.0, 2, "1", true, true, null, null, (1, 2), (1, 2), "1"].unique())`, "[1, 2.0, 1, true, null, (1, 2)]"},
+		{`let xs = []; let i = 0; while (i < 20000) { xs.append(i); xs.append(str(i)); xs.append(i); let i = i + 1; }; len(xs.unique())`, 40000},
+		{`[1, 2, 3].contains(2)`, true},
+		{`contains(["a", "b"], "c")`, false},
+		{`contains("abc", "b")`, true},
+		{`["a", "b"].indexOf("b")`, 1},
+		{`indexOf([1, 2], 3)`, -1},
+		{`indexOf("abc", "c")`, 2},
+		{`len([1].concat([2, 3], [4]))`, 4},
+		{`[1, 2].join("-")`, "1-2"},
+		{`let xs = []; xs.append(1); append(xs, 2, 3); len(xs)`, 3},
+		{`let xs = [1, 2, 3]; let ys = xs; xs.append(4); len(ys)`, 4},
+		{`let xs = [1, 2, 3]; xs.pop() + len(xs)`, 5},
+		{`let xs = [1, 2, 3]; pop(xs, 0) + xs[0]`, 3},
+		{`[].pop()`, errorMessage("pop from empty array")},
+		{`let xs = [1, 3]; xs.insert(1, 2); xs[1] + xs[2]`, 5},
+		{`let xs = [1, 2]; xs.insert(2, 3); xs[2]`, 3},
+		{`let xs = [1, 2, 3]; xs.insert(-1, 9); str(xs)`, "[1, 2, 9, 3]"},
+		{`let xs = [1, 2, 3]; xs.insert(-3, 9); str(xs)`, "[9, 1, 2, 3]"},
+		{`let xs = [1, 2, 3]; xs.insert(3, 9); str(xs)`, "[1, 2, 3, 9]"},
+		{`[1, 2].insert(-3, 9)`, errorMessage("index -3 out of range for `insert`")},
+		{`[1, 2].insert(3, 9)`, errorMessage("index 3 out of range for `insert`")},
+		{`let xs = [1]; let ys = push(xs, 2); str([xs, ys])`, "[[1], [1, 2]]"},
+		{`let xs = [1, 2, 3]; xs.removeAt(-1) + len(xs)`, 5},
+		{`[1].removeAt(1)`, errorMessage("index 1 out of range for `removeAt`")},
+		{`filter(1, fn(x) { x })`, errorMessage("argument 1 to `filter` must be ARRAY, got INTEGER")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestHashMethods(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"b": 1, "a": 2, "c": 3}.keys().join(",")`, "b,a,c"},
+		{`values({"b": 1, "a": 2}).join(",")`, "1,2"},
+		{`{"a": 1}.items()[0][1]`, 1},
+		{`{"a": 1}.has("a")`, true},
+		{`has({"a": 1}, "b")`, false},
+		{`let h = {"a": 1, "b": 2}; h.delete("a"); h.keys().join(",")`, "b"},
+		{`{"a": 1}.delete("b")`, false},
+		{`let h = {"a": 1, "b": 2, "c": 3}; h.delete("a"); h["c"]`, 3},
+		{`let m = {"a": 1, "b": 2}.merge({"b": 3, "c": 4}); m.keys().join(",") + str(m["b"])`, "a,b,c3"},
+		{`{"a": 1}.get("b", 5)`, 5},
+		{`get({"a": 1}, "a", 5)`, 1},
+		{`{"a": 1}.get("b")`, nil},
+		{`{"a": 1, "b": 2}.len()`, 2},
+		{`len({"a": 1})`, 1},
+		{`len([1, 2])`, 2},
+		{`let h = {}; let h["x"] = 1; let h["y"] = 2; let h["x"] = 3; h.keys().join(",") + str(h["x"])`, "x,y3"},
+		{`let h = {}; let h[[1]] = 1`, errorMessage("unusable as hash key: ARRAY")},
+		{`let xs = [1, 2]; let xs[1] = 5; xs[1]`, 5},
+		{`let xs = [1, 2]; let xs[2] = 5`, errorMessage("index 2 out of range for array of length 2")},
+		{`let n = 1; let n[0] = 2`, errorMessage("index assignment not supported: INTEGER")},
+		{`let h = {"a": {}}; let h["a"]["b"] = 1; h["a"]["b"]`, 1},
+		{`let grid = [[0, 0], [0, 0]]; let grid[1][0] = 5; str(grid)`, "[[0, 0], [5, 0]]"},
+		{`let h = {"xs": [1, [2, 3]]}; let h["xs"][1][0] = 9; str(h)`, "{xs: [1, [9, 3]]}"},
+		{`let h = {}; let h["a"]["b"] = 1`, errorMessage("index assignment not supported: NULL at h[a]")},
+		{`let h = {"a": 1}; let h["a"]["b"]["c"] = 1`, errorMessage("index assignment not supported: INTEGER at h[a]")},
+		{`let xs = [[1]]; let xs[0][3] = 1`, errorMessage("index 3 out of range for array of length 1")},
+		{`{"a": 1}.has([1])`, errorMessage("unusable as hash key: ARRAY")},
+		{`keys([1])`, errorMessage("argument 1 to `keys` must be HASH, got ARRAY")},
+		{`let h = {"k18gbjzvgkka": 1, "kvpq5xtw2uw": 2}; len(h) * 10 + h["k18gbjzvgkka"]`, 21},
+		{`let h = {9007199254740992: "a", 9007199254740993: "b"}; h[9007199254740992]`, "a"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestHashInspectOrder(t *testing.T) {
+	input := `let h = {"z": 1, "y": 2, 3: 3, true: 4}; let h["a"] = 5; h.delete("y"); h`
+	evaluated := testEval(input)
+	expected := `{z: 1, 3: 3, true: 4, a: 5}`
+	if evaluated.Inspect() != expected {
+		t.Errorf("hash has wrong order. got=%s, want=%s", evaluated.Inspect(), expected)
+	}
+}
+
+func TestSetsAndTuples(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len({1, 2, 2, 3})`, 3},
+		{`len(set([1, 1, "a", "a"]))`, 2},
+		{`len(set())`, 0},
+		{`len(set("hello"))`, 4},
+		{`2 in {1, 2}`, true},
+		{`"b" in {"a"}`, false},
+		{`[1] in {1}`, false},
+		{`len({1, 2} | {2, 3})`, 3},
+		{`({1, 2, 3} & {2, 3, 4}) == {3, 2}`, true},
+		{`({1, 2, 3} - {2}) == {1, 3}`, true},
+		{`{1, 2} == {1, 3}`, false},
+		{`{1, 2} != {2, 1}`, false},
+		{`let s = {1}; s.add(2); s.add(1); len(s)`, 2},
+		{`let s = {1, 2}; s.remove(1)`, true},
+		{`let s = {1, 2}; s.remove(3); len(s)`, 2},
+		{`let s = {1, 2}; remove(s, 1); 1 in s`, false},
+		{`{3, 1, 2}.values()[0]`, 3},
+		{`{"a", "b"}.has("a")`, true},
+		{`{[1]}`, errorMessage("unusable as set element: ARRAY")},
+		{`{1} | [1]`, errorMessage("type mismatch: SET | ARRAY")},
+		{`2 in [1, 2]`, true},
+		{`"a" in {"a": 1}`, true},
+		{`"ell" in "hello"`, true},
+		{`1 in 1`, errorMessage("unknown operator: INTEGER in INTEGER")},
+		{`let t = (1, "a"); t[1]`, "a"},
+		{`len((1, 2, 3))`, 3},
+		{`len(())`, 0},
+		{`(1,)[0]`, 1},
+		{`(1 + 2) * 3`, 9},
+		{`(1, 2) == (1, 2)`, true},
+		{`(1, 2) == (2, 1)`, false},
+		{`((1, 2) + (3,))[2]`, 3},
+		{`2 in (1, 2)`, true},
+		{`let h = {(1, 2): "a"}; h[(1, 2)]`, "a"},
+		{`let h = {}; let h[("x", 1)] = 5; h[("x", 1)]`, 5},
+		{`len({(1, 2), (1, 2), (2, 1)})`, 2},
+		{`let h = {((1, 2), "x"): 1}; h[((1, 2), "x")]`, 1},
+		{`{([1], 2): "a"}`, errorMessage("unusable as a hash key: TUPLE")},
+		{`let h = {}; let h[(1, {})] = 5`, errorMessage("unusable as hash key: TUPLE")},
+		{`{"a": 1}[(1, [2])]`, errorMessage("unusable as hash key: TUPLE")},
+		{`{(1, [2])}`, errorMessage("unusable as set element: TUPLE")},
+		{`(1, [2]) in {(1, 2)}`, false},
+		{`record Box(items); {Box([1]): 1}`, errorMessage("unusable as a hash key: RECORD_INSTANCE")},
+		{`record Box(items); set([Box({})])`, errorMessage("unusable as set element: RECORD_INSTANCE")},
+		{`record Box(items); {Box((1, "a")): 1}[Box((1, "a"))]`, 1},
+		{`let sumdiff = fn(a, b) { return (a + b, a - b); }; let (s, d) = sumdiff(7, 2); s * 10 + d`, 95},
+		{`let (a, b) = [1, 2]; a + b`, 3},
+		{`let (a, b) = (1, 2, 3)`, errorMessage("cannot unpack 3 values into 2 names")},
+		{`let (a, b) = 1`, errorMessage("cannot unpack INTEGER")},
+		{`tuple([1, 2])[1]`, 2},
+		{`type((1, 2)) == type(tuple())`, true},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	fact := `let fact = fn(n) { if (n < 2) { 1 } else { n * fact(n - 1) } }; `
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{fact + `str(fact(30))`, "265252859812191058636308480000000"},
+		{fact + `type(fact(30)) == type(fact(20))`, false},
+		{fact + `fact(30) / fact(28)`, 870},
+		{fact + `fact(30) > fact(29)`, true},
+		{`str(9223372036854775807 + 1)`, "9223372036854775808"},
+		{`str(-9223372036854775807 - 2)`, "-9223372036854775809"},
+		{`str(9223372036854775807 * 2)`, "18446744073709551614"},
+		{`9223372036854775807 + 1 - 1`, 9223372036854775807},
+		{`str(9223372036854775808)`, "9223372036854775808"},
+		{`9223372036854775808 - 1`, 9223372036854775807},
+		{`str(-(-9223372036854775807 - 1))`, "9223372036854775808"},
+		{`str((-9223372036854775807 - 1) / -1)`, "9223372036854775808"},
+		{`str([6 / 3, 7 / 2, -7 / 2, 0 / 5, 1 / 0])`, "[2, 3.5, -3.5, 0, inf]"},
+		{fact + `str([type(6 / 3), type(7 / 2), type(fact(30) / fact(29)), type(fact(30) / 7), type(fact(30) / 31)])`, "[<type INTEGER>, <type FLOAT>, <type INTEGER>, <type BIGINT>, <type FLOAT>]"},
+		{`9223372036854775808 == 9223372036854775807 + 1`, true},
+		{`9223372036854775808 > 1`, true},
+		{`let h = {9223372036854775808: "big"}; h[9223372036854775807 + 1]`, "big"},
+		{`100000000000000000000 / 3 > 33333333333333.0`, true},
+		{`9223372036854775808 * 2.0 > 18000000000000000000.0`, true},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestDecimals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`decimal("0.1") + decimal("0.2") == decimal("0.3")`, true},
+		{`0.1 + 0.2 == 0.3`, false},
+		{`str(decimal("1.10") + decimal("2.20"))`, "3.30"},
+		{`str(decimal("19.99") * 3)`, "59.97"},
+		{`str(decimal("1.5") * decimal("1.5"))`, "2.25"},
+		{`str(decimal("10.00") / 4)`, "2.50"},
+		{`str(decimal(10) / 4)`, "2.5"},
+		{`str(decimal(1) / 3)`, "0.3333333333333333333333333333"},
+		{`str(decimal(2) / 3)`, "0.6666666666666666666666666667"},
+		{`str(decimal(-2) / 3)`, "-0.6666666666666666666666666667"},
+		{`str(decimal("0.05") - decimal("0.10"))`, "-0.05"},
+		{`str(-decimal("1.5"))`, "-1.5"},
+		{`str(decimal(0.1))`, "0.1"},
+		{`decimal("1.50") == decimal("1.5")`, true},
+		{`decimal("2.5") > 2`, true},
+		{`decimal("2.5") < decimal("2.49")`, false},
+		{`let h = {decimal("1.5"): "a"}; h[decimal("1.50")]`, "a"},
+		{`decimal(1) / 0`, errorMessage("division by zero")},
+		{`decimal("1.5") + 1.5`, errorMessage("type mismatch: DECIMAL + FLOAT")},
+		{`1.5 * decimal("1.5")`, errorMessage("type mismatch: FLOAT * DECIMAL")},
+		{`str([decimal("1.5") == 1.5, 1.5 == decimal("1.50"), decimal("0.1") == 0.1, decimal("0.1") != 0.1])`, "[true, true, true, false]"},
+		{`str([decimal("1.5") < 2.0, 2.0 > decimal("1.5"), decimal("0.30000000000000004") > 0.3, decimal("0.3") == 0.1 + 0.2])`, "[true, true, true, false]"},
+		{`import "math"; str([decimal("1000000000000000000000000000000") < math.inf, decimal(1) > -math.inf, decimal(1) == math.nan, decimal(1) != math.nan])`, "[true, true, false, true]"},
+		{`decimal("1.2.3")`, errorMessage(`could not parse "1.2.3" as decimal`)},
+		{`decimal("abc")`, errorMessage(`could not parse "abc" as decimal`)},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFloatFormatting(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`0.1`, "0.1"},
+		{`0.1 + 0.2`, "0.30000000000000004"},
+		{`3.0`, "3.0"},
+		{`1.0 / 3`, "0.3333333333333333"},
+		{`0.000000001`, "1e-09"},
+		{`0.0001`, "0.0001"},
+		{`123456789.0 * 100000000`, "1.23456789e+16"},
+		{`-2.5`, "-2.5"},
+		{`1.0 / 0`, "inf"},
+		{`-1.0 / 0`, "-inf"},
+		{`float("nan")`, "nan"},
+		{`str(1.5)`, "1.5"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s printed wrong. got=%s, want=%s", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestNumericBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`int(3.9)`, 3},
+		{`int(-3.9)`, -3},
+		{`int("42")`, 42},
+		{`int(true)`, 1},
+		{`int(decimal("-7.5"))`, -7},
+		{`str(int(100000000000000000000.0))`, "100000000000000000000"},
+		{`int("4x")`, errorMessage(`could not parse "4x" as integer in base 10`)},
+		{`int(float("nan"))`, errorMessage("cannot convert nan to integer")},
+		{`int([])`, errorMessage("argument to `int` not supported, got ARRAY")},
+		{`str(float(2))`, "2.0"},
+		{`str(float("1e3"))`, "1000.0"},
+		{`str(float(decimal("0.25")))`, "0.25"},
+		{`str(float("-inf"))`, "-inf"},
+		{`float("abc")`, errorMessage(`could not parse "abc" as float`)},
+		{`parseInt("ff", 16)`, 255},
+		{`parseInt("-101", 2)`, -5},
+		{`parseInt("0x1f", 0)`, 31},
+		{`str(parseInt("ffffffffffffffffff", 16))`, "4722366482869645213695"},
+		{`parseInt("12", 1)`, errorMessage("base to `parseInt` must be 0 or between 2 and 36, got 1")},
+		{`parseInt("z", 10)`, errorMessage(`could not parse "z" as integer in base 10`)},
+		{`round(2.5)`, 2},
+		{`round(3.5)`, 4},
+		{`round(-2.7)`, -3},
+		{`str(round(2.675, 2))`, "2.67"},
+		{`str(round(1.23456, 3))`, "1.235"},
+		{`str(round(decimal("2.345"), 2))`, "2.34"},
+		{`str(round(decimal("2.355"), 2))`, "2.36"},
+		{`round(7, 2)`, 7},
+		{`str([round(1.5, 100000000000), round(0.1, 324), round(0.000000001, 9223372036854775807)])`, "[1.5, 0.1, 1e-09]"},
+		{`str(round(decimal("2.345"), 100000000000))`, "2.345"},
+		{`round(1.5, -1)`, errorMessage("digits to `round` must not be negative, got -1")},
+		{`abs(-5)`, 5},
+		{`str(abs(-2.5))`, "2.5"},
+		{`str(abs(-9223372036854775807 - 1))`, "9223372036854775808"},
+		{`str(abs(decimal("-1.10")))`, "1.10"},
+		{`min(3, 1, 2)`, 1},
+		{`max([3, 1, 2])`, 3},
+		{`max("a", "c", "b")`, "c"},
+		{`str(min(2, 1.5))`, "1.5"},
+		{`isNaN(max(1, float("nan"), 3))`, true},
+		{`min([])`, errorMessage("`min` of an empty sequence")},
+		{`max(1, "a")`, errorMessage("type mismatch: INTEGER < STRING")},
+		{`let nan = float("nan"); nan == nan`, false},
+		{`let nan = float("nan"); nan != nan`, true},
+		{`float("nan") < 1.0`, false},
+		{`float("nan") > 1.0`, false},
+		{`float("inf") > 9223372036854775808`, true},
+		{`isInf(-1.0 / 0)`, true},
+		{`isNaN(1.0)`, false},
+		{`let nan = float("nan"); len({nan, nan})`, 1},
+		{`let nan = float("nan"); {nan: 1}[nan]`, 1},
+		{`str([3.0, float("nan"), 1.0].sort())`, "[1.0, 3.0, nan]"},
+		{`str(-(1.5))`, "-1.5"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMathModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "math"; math.sqrt(16) == 4.0`, true},
+		{`import "math"; str(math.sqrt(2))`, "1.4142135623730951"},
+		{`import "math"; str(math.sqrt(-1))`, "nan"},
+		{`import "math"; math.pow(2, 10)`, 1024},
+		{`import "math"; str(math.pow(2, 100))`, "1267650600228229401496703205376"},
+		{`import "math"; str(math.pow(2, -1))`, "0.5"},
+		{`import "math"; str(math.pow(2.0, 0.5))`, "1.4142135623730951"},
+		{`import "math"; str(math.pow(decimal("1.1"), 2))`, "1.21"},
+		{`import "math"; math.pow(2, 100000000)`, errorMessage("exponent 100000000 too large for `pow`")},
+		{`import "math"; math.pow(3, 9223372036854775808)`, errorMessage("exponent 9223372036854775808 too large for `pow`")},
+		{`import "math"; math.pow(decimal("0.1"), 100000000)`, errorMessage("exponent 100000000 too large for `pow`")},
+		{`import "math"; math.pow(-1, 9223372036854775809)`, -1},
+		{`import "math"; len(str(math.pow(10, 100000)))`, 100001},
+		{`import "math"; setattr(math, "pi", 3); math.pi`, 3},
+		{`import "math"; math.pi > 3`, true},
+		{`import "math"; let m = math; import "math"; setattr(math, "pi", 1); m.pi > 3`, true},
+		{`import "math"; math.floor(2.7)`, 2},
+		{`import "math"; math.floor(-2.5)`, -3},
+		{`import "math"; math.ceil(2.1)`, 3},
+		{`import "math"; math.floor(7)`, 7},
+		{`import "math"; math.floor(decimal("-1.5"))`, -2},
+		{`import "math"; math.ceil(decimal("1.01"))`, 2},
+		{`import "math"; math.floor(math.nan)`, errorMessage("cannot convert nan to integer")},
+		{`import "math"; math.isqrt(17)`, 4},
+		{`import "math"; math.isqrt(-1)`, errorMessage("argument to `isqrt` must not be negative")},
+		{`import "math"; math.gcd(12, 18)`, 6},
+		{`import "math"; math.gcd(-4, 0)`, 4},
+		{`import "math"; math.lcm(4, 6)`, 12},
+		{`import "math"; math.lcm(0, 6)`, 0},
+		{`import "math"; math.gcd(1.5, 2)`, errorMessage("argument 1 to `gcd` must be INTEGER, got FLOAT")},
+		{`import "math"; math.sin(0) == 0.0`, true},
+		{`import "math"; math.cos(math.pi) == -1.0`, true},
+		{`import "math"; round(math.atan2(1, 1) * 4, 10) == round(math.pi, 10)`, true},
+		{`import "math"; math.log(math.e) == 1.0`, true},
+		{`import "math"; round(math.log(8, 2), 10) == 3.0`, true},
+		{`import "math"; math.log10(1000) == 3.0`, true},
+		{`import "math"; math.log2(8) == 3.0`, true},
+		{`import "math"; math.exp(0) == 1.0`, true},
+		{`import "math"; str(math.pi)`, "3.141592653589793"},
+		{`import "math"; isInf(math.inf)`, true},
+		{`import "math"; isNaN(math.nan)`, true},
+		{`import "math"; math.sqrt("4")`, errorMessage("argument 1 to `sqrt` must be a number, got STRING")},
+		{`import "math"; math.missing`, errorMessage("module math has no name missing")},
+		{`import "math" as "m"; m.floor(1.5)`, 1},
+		{`import "math"; str(math)`, "module math"},
+		{`import "no/such/module"`, errorMessage("could not import no/such/module: no builtin module or file no/such/module.monkey")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestRandomModule(t *testing.T) {
+	draw := `let draw = fn(g) { str([g.int(1, 100), g.float(), g.choice(["a", "b", "c"]), g.sample([1, 2, 3, 4], 2)]) }; `
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "random"; random.seed(7); let a = random.int(0, 1000000); random.seed(7); a == random.int(0, 1000000)`, true},
+		{`import "random"; ` + draw + `draw(random.Generator(42)) == draw(random.Generator(42))`, true},
+		{`import "random"; ` + draw + `draw(random.Generator(1)) == draw(random.Generator(2))`, false},
+		{`import "random"; let g = random.Generator(3); let a = g.int(0, 1000000); random.seed(3); random.int(0, 1000000) == a`, true},
+		{`import "random"; let g = random.Generator(5); let xs = map([1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20], fn(i) { g.int(1, 3) }); str([min(xs), max(xs), len(xs.unique())])`, "[1, 3, 3]"},
+		{`import "random"; let g = random.Generator(5); let xs = map([1, 2, 3, 4, 5, 6, 7, 8], fn(i) { g.int(-2, 2) }); min(xs) > -3`, true},
+		{`import "random"; random.Generator(0).int(4, 4)`, 4},
+		{`import "random"; str(random.Generator(0).int(9223372036854775808, 9223372036854775808))`, "9223372036854775808"},
+		{`import "random"; let f = random.Generator(9).float(); f < 1.0`, true},
+		{`import "random"; let xs = [1, 2, 3, 4, 5]; random.Generator(1).shuffle(xs); str(xs)`, "[3, 1, 2, 5, 4]"},
+		{`import "random"; let g = random.Generator(2); str([type(g) == random.Generator, isinstance(g, random.Generator), type(g)])`, "[true, true, class Generator]"},
+		{`import "random"; let xs = [1, 2, 3, 4, 5]; random.shuffle(xs); str(xs.sort())`, "[1, 2, 3, 4, 5]"},
+		{`import "random"; len(random.sample([1, 2, 3], 3).unique())`, 3},
+		{`import "random"; random.choice((7,))`, 7},
+		{`import "random"; random.int(2, 1)`, errorMessage("empty range for `int`: 2 > 1")},
+		{`import "random"; random.choice([])`, errorMessage("cannot choose from an empty sequence")},
+		{`import "random"; random.sample([1], 2)`, errorMessage("sample size 2 out of range for a sequence of 1 elements")},
+		{`import "random"; random.seed("x")`, errorMessage("argument 1 to `seed` must be INTEGER, got STRING")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestJSONModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "json"; json.encode({"b": 1, "a": [true, null, 1.5, "x"]})`, `{"b":1,"a":[true,null,1.5,"x"]}`},
+		{`import "json"; json.encode([1, [2]], 2)`, "[\n  1,\n  [\n    2\n  ]\n]"},
+		{`import "json"; json.encode({"a": 1}, "	")`, "{\n\t\"a\": 1\n}"},
+		{`import "json"; json.encode([1], 0)`, "[\n1\n]"},
+		{`import "json"; json.encode([1], -1)`, errorMessage("indent of `encode` must be between 0 and 16, got -1")},
+		{`import "json"; json.encode([1], 100000000000)`, errorMessage("indent of `encode` must be between 0 and 16, got 100000000000")},
+		{`import "json"; json.encode("<a & b>")`, `"<a & b>"`},
+		{`import "json"; json.encode((1, 2.0, 100000000000000000000, decimal("1.50")))`, `[1,2.0,100000000000000000000,1.50]`},
+		{`import "json"; record Point(x, y); json.encode(Point(1, 2))`, `{"x":1,"y":2}`},
+		{`import "json"; class User() { let name = null; let __New__ = fn(name) { let self.name = name }; let __json__ = fn() { {"name": self.name} } }; json.encode([User("ann")])`, `[{"name":"ann"}]`},
+		{`import "json"; let text = json.encode({"z": 1, "a": [1, 2.5, "s", false, null]}); json.encode(json.decode(text)) == text`, true},
+		{`import "json"; str(json.decode(json.encode({"z": 1, "y": 2, "x": 3})).keys())`, "[z, y, x]"},
+		{`import "json"; json.encode({1: 2})`, errorMessage("JSON object keys must be STRING, got INTEGER")},
+		{`import "json"; import "math"; json.encode(math.nan)`, errorMessage("cannot encode nan as JSON")},
+		{`import "json"; let xs = [1]; xs.append(xs); json.encode(xs)`, errorMessage("cannot encode a cyclic ARRAY as JSON")},
+		{`import "json"; json.encode(fn() { 1 })`, errorMessage("cannot encode FUNCTION as JSON")},
+		{`import "json"; class A() {}; json.encode(A())`, errorMessage("cannot encode <Instance of Class A> as JSON: it has no __json__ method")},
+		{`import "json"; class A() { let __json__ = fn() { {"me": self} } }; json.encode(A())`, errorMessage("cannot encode a cyclic CLASS_INSTANCE as JSON")},
+		{`import "json"; class A() { let __json__ = fn() { [1, [self]] } }; json.encode({"a": A()})`, errorMessage("cannot encode a cyclic CLASS_INSTANCE as JSON")},
+		{`import "json"; class A() { let __json__ = fn() { 1 } }; let a = A(); json.encode([a, a, {"b": a}])`, `[1,1,{"b":1}]`},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestJSONDecode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"b": [1, 2.5, true, null], "a": "\u00e9"}`, `{b: [1, 2.5, true, null], a: é}`},
+		{`[9223372036854775808, -1, 1e2]`, `[9223372036854775808, -1, 100.0]`},
+		{` "x" `, `x`},
+		{`{"a": 1, "a": 2}`, `{a: 2}`},
+		{"{\n  \"a\": 1,\n  \"b\": x\n}", "invalid JSON at line 3, column 8: invalid character 'x' looking for beginning of value"},
+		{`[1, 2`, "invalid JSON at line 1, column 6: unexpected end of JSON input"},
+		{`[1] [2]`, "invalid JSON at line 1, column 5: unexpected data after top-level value"},
+		{`{"a" 1}`, "invalid JSON at line 1, column 6: invalid character '1' after object key"},
+	}
+
+	for _, tt := range tests {
+		result := jsonDecode(&object.String{Value: tt.input})
+		if err, ok := result.(*object.Error); ok {
+			if err.Message != tt.expected {
+				t.Errorf("wrong error for %q. got=%q, want=%q", tt.input, err.Message, tt.expected)
+			}
+			continue
+		}
+		if result.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, result.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestTimeModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "time"; str(time.DateTime(2024, 3, 1, 12, 30))`, "2024-03-01T12:30:00Z"},
+		{`import "time"; str(time.DateTime(2024, 7, 1, 9, 0, 0, "Europe/Paris"))`, "2024-07-01T09:00:00+02:00"},
+		{`import "time"; time.DateTime(2024, 7, 1, 9, 0, 0, "Europe/Paris").in("America/New_York").hour()`, 3},
+		{`import "time"; time.DateTime(2024, 7, 1, 9, 0, 0, "Europe/Paris") == time.DateTime(2024, 7, 1, 7)`, true},
+		{`import "time"; let d = time.DateTime(2024, 2, 29, 8, 5, 3); str([d.year(), d.month(), d.day(), d.yearDay(), d.weekday()])`, "[2024, 2, 29, 60, Thursday]"},
+		{`import "time"; time.DateTime(2024, 2, 29, 8, 5, 3).format("%A %d %B %Y, %I:%M:%S %p (%Z) 100%%")`, "Thursday 29 February 2024, 08:05:03 AM (UTC) 100%"},
+		{`import "time"; time.parse("2024-03-01T12:30:00.250+01:00").format("%Y-%m-%d %H:%M:%S.%f %z")`, "2024-03-01 12:30:00.250000 +0100"},
+		{`import "time"; str(time.parse("01/03/2024 18:45", "%d/%m/%Y %H:%M", "Asia/Tokyo"))`, "2024-03-01T18:45:00+09:00"},
+		{`import "time"; time.parse("2024-03-01 10:00:00.5", "%Y-%m-%d %H:%M:%S.%f").nanosecond()`, 500000000},
+		{`import "time"; str(time.parse("2024-03-05 day 2", "%Y-%m-%d day 2"))`, "2024-03-05T00:00:00Z"},
+		{`import "time"; str(time.parse("Mon 15 Jan 2024 at 03PM MST", "Mon %d %b %Y at %I%p MST"))`, "2024-01-15T15:00:00Z"},
+		{`import "time"; str(time.parse("20240305 1430 +0100", "%Y%m%d %H%M %z"))`, "2024-03-05T14:30:00+01:00"},
+		{`import "time"; str(time.parse("5 March 2024, 50%", "%e %B %Y, 50%%"))`, "2024-03-05T00:00:00Z"},
+		{`import "time"; time.parse("2024-03-05 day 3", "%Y-%m-%d day 2")`, errorMessage(`could not parse "2024-03-05 day 3" as a date time`)},
+		{`import "time"; time.parse("2024-03-05!", "%Y-%m-%d")`, errorMessage(`could not parse "2024-03-05!" as a date time`)},
+		{`import "time"; time.fromUnix(86400).rfc3339()`, "1970-01-02T00:00:00Z"},
+		{`import "time"; time.DateTime(2000, 1, 1).unix()`, 946684800},
+		{`import "time"; str(time.DateTime(2024, 3, 1) - time.DateTime(2024, 2, 28, 12))`, "36h0m0s"},
+		{`import "time"; str(time.DateTime(2024, 3, 1) + time.hours(36))`, "2024-03-02T12:00:00Z"},
+		{`import "time"; str(time.days(1) + time.DateTime(2024, 2, 28))`, "2024-02-29T00:00:00Z"},
+		{`import "time"; str(time.DateTime(2024, 3, 1) - time.minutes(1))`, "2024-02-29T23:59:00Z"},
+		{`import "time"; str([time.minutes(90) * 2, 3 * time.seconds(1.5), time.hours(1) / 4, -time.seconds(1)])`, "[3h0m0s, 4.5s, 15m0s, -1s]"},
+		{`import "time"; time.minutes(90).hours()`, "1.5"},
+		{`import "time"; time.hours(1) / time.minutes(20)`, "3.0"},
+		{`import "time"; time.parseDuration("1h30m") == time.minutes(90)`, true},
+		{`import "time"; time.seconds(1) < time.milliseconds(1001)`, true},
+		{`import "time"; time.DateTime(2024, 1, 1) > time.DateTime(2023, 12, 31)`, true},
+		{`import "time"; {time.seconds(60): "a"}[time.minutes(1)]`, "a"},
+		{`import "time"; let c = time.clock(); time.sleep(time.milliseconds(5)); time.sleep(5); time.clock() - c > 0.009`, true},
+		{`import "time"; time.now("UTC").zone()`, "UTC"},
+		{`import "time"; time.DateTime(2024, 1, 1) + 1`, errorMessage("type mismatch: DATETIME + INTEGER")},
+		{`import "time"; time.hours(1) / 0`, errorMessage("division by zero")},
+		{`import "time"; time.hours(100000000000)`, errorMessage("duration out of range")},
+		{`import "time"; time.hours(100000000000.5)`, errorMessage("duration out of range")},
+		{`import "time"; time.hours(2000000) + time.hours(2000000)`, errorMessage("duration out of range")},
+		{`import "time"; time.hours(2000000) - time.hours(-2000000)`, errorMessage("duration out of range")},
+		{`import "time"; time.hours(2000000) * 2`, errorMessage("duration out of range")},
+		{`import "time"; 2.5 * time.hours(2000000)`, errorMessage("duration out of range")},
+		{`import "time"; time.hours(1) / 0.0000000001`, errorMessage("duration out of range")},
+		{`import "time"; time.now("Mars/Olympus")`, errorMessage("unknown time zone Mars/Olympus")},
+		{`import "time"; time.parse("yesterday")`, errorMessage(`could not parse "yesterday" as a date time`)},
+		{`import "time"; time.now().format("%Q")`, errorMessage(`unknown directive %Q in format "%Q"`)},
+		{`import "time"; time.now().year(1)`, errorMessage("wrong number of arguments. got=1, want=0")},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		if f, ok := result.(*object.Float); ok {
+			result = &object.String{Value: f.Inspect()}
+		}
+		testExpectedObject(t, result, tt.expected)
+	}
+}
+
+func TestRegexModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`r/^\d+$/.match("2024")`, true},
+		{`import "re"; re.match("^[a-z]+$", "abc1")`, false},
+		{`r/hello/i.match("Say HELLO")`, true},
+		{`let r = 10; r/2`, 5},
+		{`let r = 10; r / 2`, 5},
+		{`let r = 12; let w = 3; r/w/1`, 4},
+		{`let r = 12; let b = 3; let c = 2; let a = r/b/c; a`, 2},
+		{`let r = 12; let b = 3; let c = 2; let f = fn(x) { x }; f(r/b/c)`, 2},
+		{`let r = 12; let b = 3; let i = 2; r/b/i.match("B")`, true},
+		{`let r = 12; let w = 3; str([r/w, (r)/2, r/4])`, "[4, 6, 3]"},
+		{`let r = 12
+r/3`, 4},
+		{`str(r/(\w+)@(\w+)/.find("mail ann@example now"))`, "Match(text=ann@example, start=5, end=16, groups=[ann, example], named={})"},
+		{`let m = r/(?P<year>\d{4})-(?P<month>\d\d)(-x)?/.find("on 2024-03"); str([m.named, m.groups])`, "[{year: 2024, month: 03}, [2024, 03, null]]"},
+		{`r/ä(\w)/.find("ééä1").start`, 2},
+		{`r/x/.find("abc")`, nil},
+		{`str(map(r/\d+/.findAll("1 22 333"), fn(m) { m.text }))`, "[1, 22, 333]"},
+		{`len(r/\d/.findAll("12345", 2))`, 2},
+		{`r/(\w+)=(\w+)/.replace("a=1 b=2", "$2=$1")`, "1=a 2=b"},
+		{`r/(?P<k>\w+)=\w+/.replace("a=1 b=2", "${k}", 1)`, "a b=2"},
+		{`r/\d+/.replace("3 apples and 12 pears", fn(m) { str(int(m.text) * 2) })`, "6 apples and 24 pears"},
+		{`str(r/\s*,\s*/.split("a , b,c"))`, "[a, b, c]"},
+		{`import "re"; str(re.split(",", "a,b,c", 2))`, "[a, b,c]"},
+		{`import "re"; let rx = re.compile("^abc$", "im"); rx.match("x
+ABC")`, true},
+		{`import "re"; re.match(re.escape("a.b"), "axb")`, false},
+		{`str(r/a\/b/)`, `r/a\/b/`},
+		{`type(r/a/) == type(r/b/)`, true},
+		{`r/(/`, errorMessage("invalid regex: error parsing regexp: missing closing ): `(`")},
+		{`import "re"; re.compile("a", "x")`, errorMessage("unknown regex flag x")},
+		{`r/a/.replace("a", fn(m) { 1 })`, errorMessage("replacement function must return STRING, got INTEGER")},
+		{`import "re"; re.find(1, "a")`, errorMessage("argument 1 to `find` must be REGEX or STRING, got INTEGER")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestSlicing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1, 2, 3, 4][1:3]`, "[2, 3]"},
+		{`[1, 2, 3, 4][:-1]`, "[1, 2, 3]"},
+		{`[1, 2, 3, 4][-2:]`, "[3, 4]"},
+		{`[1, 2, 3][5:]`, "[]"},
+		{`[1, 2, 3][2:1]`, "[]"},
+		{`[1, 2, 3][-10:10]`, "[1, 2, 3]"},
+		{`let a = [1, 2]; let b = a[:]; b.append(3); str(a)`, "[1, 2]"},
+		{`(1, 2, 3)[1:]`, "(2, 3)"},
+		{`"héllo"[1:4]`, "éll"},
+		{`[1, 2, 3][null:2]`, "[1, 2]"},
+		{`[1, 2, 3]["a":]`, "Error: slice bounds must be INTEGER, got STRING"},
+		{`5[1:]`, "Error: slice operator not supported: INTEGER"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		if result.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. got=%q, want=%q", tt.input, result.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestBytes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`b"\x89PNG\r\n"`, `b"\x89PNG\r\n"`},
+		{`b"a\"b\\"`, `b"a\"b\\"`},
+		{`b"abc"[1]`, "98"},
+		{`b"abc"[3]`, "null"},
+		{`b"abcdef"[1:-1]`, `b"bcde"`},
+		{`len(b"\x00\x01")`, "2"},
+		{`b"ab" + b"\xff"`, `b"ab\xff"`},
+		{`b"ab" == b"ab"`, "true"},
+		{`b"ab" < b"b"`, "true"},
+		{`str([255 in b"\xff", 256 in b"\xff", b"PN" in b"\x89PNG"])`, "[true, false, true]"},
+		{`{b"k": 1}[b"k"]`, "1"},
+		{`"héllo".encode()`, `b"h\xc3\xa9llo"`},
+		{`"héllo".encode("Latin-1")`, `b"h\xe9llo"`},
+		{`"hé".encode("utf-16le")`, `b"h\x00\xe9\x00"`},
+		{`"hé".encode("UTF16BE").decode("utf-16be")`, "hé"},
+		{`b"h\xe9".decode("latin1")`, "hé"},
+		{`b"h\xc3\xa9".decode()`, "hé"},
+		{`bytes("hé", "utf-8") == "hé".encode()`, "true"},
+		{`bytes([0, 127, 255])`, `b"\x00\x7f\xff"`},
+		{`str(b"\x01\x02".toArray())`, "[1, 2]"},
+		{`b"\xde\xad".hex()`, "dead"},
+		{`"é".encode("ascii")`, "Error: cannot encode 'é' as ascii"},
+		{`b"a\xff".decode()`, "Error: invalid utf-8 data at byte 1"},
+		{`b"\x80".decode("ascii")`, "Error: invalid ascii data at byte 0"},
+		{`b"a".decode("ebcdic")`, "Error: unknown encoding ebcdic"},
+		{`bytes([256])`, "Error: bytes must be INTEGER from 0 to 255, got 256"},
+		{`b"a" + "b"`, "Error: type mismatch: BYTES + STRING"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		if result.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. got=%q, want=%q", tt.input, result.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestBinaryModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "binary"; binary.encodeBase64(b"\xfb\xff")`, "+/8="},
+		{`import "binary"; binary.encodeBase64(b"\xfb\xff", true)`, "-_8="},
+		{`import "binary"; binary.encodeBase64("hi")`, "aGk="},
+		{`import "binary"; binary.decodeBase64("aGk=")`, `b"hi"`},
+		{`import "binary"; binary.decodeBase64("-_8=", true)`, `b"\xfb\xff"`},
+		{`import "binary"; binary.encodeHex(b"\x00\xab")`, "00ab"},
+		{`import "binary"; binary.decodeHex("00AB")`, `b"\x00\xab"`},
+		{`import "binary"; binary.pack(">HI", 1, 2)`, `b"\x00\x01\x00\x00\x00\x02"`},
+		{`import "binary"; binary.pack("<hb", -2, -1)`, `b"\xfe\xff\xff"`},
+		{`import "binary"; binary.pack("Q", 18446744073709551615)`, `b"\xff\xff\xff\xff\xff\xff\xff\xff"`},
+		{`import "binary"; binary.unpack("<hB", b"\xfe\xff\xff")`, "(-2, 255)"},
+		{`import "binary"; binary.unpack("Qq", binary.pack("Qq", 18446744073709551615, -9223372036854775808))`, "(18446744073709551615, -9223372036854775808)"},
+		{`import "binary"; let (a, b) = binary.unpack("<ii", binary.pack("<ii", 7, -7)); a + b`, "0"},
+		{`import "binary"; binary.pack("B", 256)`, "Error: 256 does not fit in format B"},
+		{`import "binary"; binary.pack("b", -129)`, "Error: -129 does not fit in format b"},
+		{`import "binary"; binary.pack("HH", 1)`, "Error: format needs 2 values, got 1"},
+		{`import "binary"; binary.pack("x", 1)`, `Error: unknown code x in format "x"`},
+		{`import "binary"; binary.unpack("I", b"\x00")`, "Error: format needs 4 bytes, got 1"},
+		{`import "binary"; binary.decodeHex("abc")`, "Error: invalid hex: encoding/hex: odd length hex string"},
+		{`import "binary"; binary.decodeBase64("!")`, "Error: invalid base64: illegal base64 data at input byte 0"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		if result.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. got=%q, want=%q", tt.input, result.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestCryptoModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "crypto"; crypto.md5("abc").hex()`, "900150983cd24fb0d6963f7d28e17f72"},
+		{`import "crypto"; crypto.sha1(b"abc").hex()`, "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{`import "crypto"; crypto.sha256("abc").hex()`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{`import "crypto"; len(crypto.sha512(""))`, 64},
+		{`import "crypto"; crypto.hmac("sha256", "key", "The quick brown fox jumps over the lazy dog").hex()`, "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+		{`import "crypto"; crypto.hmac("md5", b"key", "The quick brown fox jumps over the lazy dog").hex()`, "80070713463e7749b90c2dc24911e275"},
+		{`import "crypto"; crypto.compare("secret", b"secret")`, true},
+		{`import "crypto"; crypto.compare("secret", "secreT")`, false},
+		{`import "crypto"; len(crypto.randomBytes(16))`, 16},
+		{`import "crypto"; crypto.randomBytes(16) != crypto.randomBytes(16)`, true},
+		{`import "crypto"; crypto.uuid() != crypto.uuid()`, true},
+		{`import "crypto"; r/^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$/.match(crypto.uuid())`, true},
+		{`import "crypto"; crypto.hmac("sha3", "k", "d")`, errorMessage("unknown hash algorithm sha3")},
+		{`import "crypto"; crypto.sha256(1)`, errorMessage("argument 1 to `sha256` must be BYTES or STRING, got INTEGER")},
+		{`import "crypto"; crypto.randomBytes(-1)`, errorMessage("argument to `randomBytes` must not be negative")},
+		{`import "crypto"; len(crypto.randomBytes(1048576))`, 1048576},
+		{`import "crypto"; crypto.randomBytes(1048577)`, errorMessage("argument to `randomBytes` must be at most 1048576")},
+		{`import "crypto"; crypto.randomBytes(9223372036854775807)`, errorMessage("argument to `randomBytes` must be at most 1048576")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/missing":
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Test", "yes")
+		json.NewEncoder(w).Encode(map[string]string{
+			"method": r.Method,
+			"body":   string(body),
+			"type":   r.Header.Get("Content-Type"),
+			"token":  r.Header.Get("X-Token"),
+		})
+	}))
+	defer server.Close()
+
+	setup := `import "http"; let url = "` + server.URL + `"; `
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{setup + `http.get(url).status`, 200},
+		{setup + `http.get(url).headers["X-Test"]`, "yes"},
+		{setup + `http.get(url).json()["method"]`, "GET"},
+		{setup + `http.get(url, {"headers": {"X-Token": "abc"}}).json()["token"]`, "abc"},
+		{setup + `let r = http.post(url, "hello").json(); str([r["method"], r["body"]])`, "[POST, hello]"},
+		{setup + `let r = http.post(url, {"a": [1, 2]}).json(); str([r["body"], r["type"]])`, `[{"a":[1,2]}, application/json]`},
+		{setup + `http.post(url, b"raw").json()["body"]`, "raw"},
+		{setup + `str([http.post(url, "text").json()["type"], http.post(url, b"raw").json()["type"]])`, "[, ]"},
+		{setup + `http.request({"method": "put", "url": url, "body": "x"}).json()["method"]`, "PUT"},
+		{setup + `http.request({"url": url + "/missing"}).status`, 404},
+		{setup + `http.get(url + "/missing").body`, "404 page not found\n"},
+		{setup + `let (r, err) = try(http.get, url + "/slow", {"timeout": 20}); r`, nil},
+		{setup + `http.get(url + "/slow", {"timeout": 0}).status`, 200},
+		{setup + `http.get(url, {"timeout": -1})`, errorMessage("`get` timeout must not be negative")},
+		{setup + `let r = http.get(url); str([isinstance(r, http.Response), type(r)])`, "[true, class Response]"},
+		{`import "http"; http.Response()`, errorMessage("Response objects cannot be created directly")},
+		{setup + `http.get(url, {"retries": 3})`, errorMessage("unknown `get` option retries")},
+		{setup + `http.post(url, 1)`, errorMessage("`post` body must be STRING, BYTES, HASH or ARRAY, got INTEGER")},
+		{`import "http"; http.request({"method": "GET"})`, errorMessage("`request` needs a url")},
+		{`import "http"; http.get(1)`, errorMessage("`get` url must be STRING, got INTEGER")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+
+	httpTimeout = 20 * time.Millisecond
+	defer func() { httpTimeout = 30 * time.Second }()
+	testExpectedObject(t, testEval(setup+`let (r, err) = try(http.get, url + "/slow"); str([r, err != null])`), "[null, true]")
+	testExpectedObject(t, testEval(setup+`http.get(url + "/slow", {"timeout": 1000}).status`), 200)
+
+	maxHTTPBodySize = 20
+	defer func() { maxHTTPBodySize = 10 << 20 }()
+	testExpectedObject(t, testEval(setup+`http.get(url + "/missing").body`), "404 page not found\n")
+	testExpectedObject(t, testEval(setup+`http.get(url)`), errorMessage("get: response body is larger than 20 bytes"))
+}
+
+func TestHTTPServer(t *testing.T) {
+	setup := `import "http"; let get = fn(server, path) { http.get("http://" + server.addr + path) }; `
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{setup + `let s = http.serve("127.0.0.1:0", fn(req) { "hello " + req.path }); let r = get(s, "/world"); s.shutdown(); s.wait(); r.body`, "hello /world"},
+		{setup + `let s = http.serve("127.0.0.1:0", fn(req) { req.query["q"] }); let r = get(s, "/?q=monkey&q=x"); s.shutdown(); r.body`, "monkey"},
+		{setup + `let s = http.serve("127.0.0.1:0", fn(req) { {"status": 201, "headers": {"X-Id": "7"}, "body": {"got": req.json()}} }); let r = http.post("http://" + s.addr, [1, 2]); s.shutdown(); str([r.status, r.headers["X-Id"], r.headers["Content-Type"], r.body])`, `[201, 7, application/json, {"got":[1,2]}]`},
+		{setup + `let s = http.serve("127.0.0.1:0", fn(req) { 1 / 0 }); let r = get(s, "/"); s.shutdown(); r.status`, 500},
+		{setup + `let s = http.serve("127.0.0.1:0", fn(req) { {"status": "ok"} }); let r = get(s, "/"); s.shutdown(); r.body`, "invalid response status ok\n"},
+		{setup + `let seen = []; let s = http.serve("127.0.0.1:0", fn(req) { seen.append(req.path); str(len(seen)) }); get(s, "/"); get(s, "/"); let r = get(s, "/"); s.shutdown(); r.body`, "3"},
+		{setup + `let s = http.serve("127.0.0.1:0", fn(req) { s.shutdown(); "bye" }); let r = get(s, "/"); s.wait(); r.body`, "bye"},
+		{setup + `let router = http.Router().get("/users/:id", fn(req) { "user " + req.params["id"] }).post("/users", fn(req) { {"status": 201} }); let s = http.serve("127.0.0.1:0", router); let r = [get(s, "/users/42/").body, http.post("http://" + s.addr + "/users", "").status, get(s, "/users").status, get(s, "/nothing").status]; s.shutdown(); str(r)`, "[user 42, 201, 405, 404]"},
+		{setup + `let router = http.Router(); router.route("patch", "/x", fn(req) { req.method }); let s = http.serve("127.0.0.1:0", router); let r = http.request({"method": "PATCH", "url": "http://" + s.addr + "/x"}); s.shutdown(); r.body`, "PATCH"},
+		{setup + `let s = http.serve("127.0.0.1:0", fn(req) { if (req.path == "/b") { return b"raw"; }; "text" }); let r = [get(s, "/").headers["Content-Type"], get(s, "/b").headers["Content-Type"]]; s.shutdown(); str(r)`, "[text/plain; charset=utf-8, application/octet-stream]"},
+		{setup + `let s = http.serve("127.0.0.1:0", fn(req) { if (req.path == "/inner") { return "inner"; }; "outer " + get(s, "/inner").body }); let r = get(s, "/"); s.shutdown(); r.body`, "outer inner"},
+		{setup + `let kinds = []; let s = http.serve("127.0.0.1:0", fn(req) { kinds.append(isinstance(req, http.Request)); "" }); get(s, "/"); s.shutdown(); str([isinstance(s, http.Server), isinstance(http.Router(), http.Router), kinds])`, "[true, true, [true]]"},
+		{`import "http"; http.Request()`, errorMessage("Request objects cannot be created directly")},
+		{`import "http"; http.Router().handle(1)`, errorMessage("argument to `handle` must be a Request, got INTEGER")},
+		{`import "http"; http.serve("127.0.0.1:0", 1)`, errorMessage("argument 2 to `serve` must be FUNCTION or an object with a handle method, got INTEGER")},
+		{`import "http"; http.Router().get("/", 1)`, errorMessage("argument 2 to `get` must be FUNCTION, got INTEGER")},
+		{`import "http"; http.serve("nowhere", fn(req) { "" })`, errorMessage("serve: listen tcp: address nowhere: missing port in address")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+
+	maxHTTPBodySize = 32
+	defer func() { maxHTTPBodySize = 10 << 20 }()
+	testExpectedObject(t, testEval(setup+`let s = http.serve("127.0.0.1:0", fn(req) { str(len(req.body)) }); let r = [http.post("http://" + s.addr, "x".repeat(32)).body, http.post("http://" + s.addr, "x".repeat(33)).status]; s.shutdown(); str(r)`), "[32, 413]")
+}
+
+func TestHTTPServerWhileScriptRuns(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	// the requests arrive while the script is busy with the hash its handler also changes
+	statuses := make(chan int, 20)
+	go func() {
+		defer close(statuses)
+		for sent := 0; sent < 20; {
+			response, err := http.Get("http://" + addr + "/")
+			if err != nil {
+				time.Sleep(time.Millisecond)
+				continue
+			}
+			response.Body.Close()
+			statuses <- response.StatusCode
+			sent++
+		}
+	}()
+
+	input := `import "http";
+let work = {};
+let s = http.serve("` + addr + `", fn(req) {
+	let work["request " + str(len(work))] = true;
+	if (len(work) == 20020) { s.shutdown(); }
+	"ok"
+});
+let i = 0;
+while (i < 20000) { let work[i] = i; let i = i + 1; }
+s.wait();
+len(work)`
+	testExpectedObject(t, testEval(input), 20020)
+	for status := range statuses {
+		if status != http.StatusOK {
+			t.Errorf("wrong status. got=%d", status)
+		}
+	}
+}
+
+func TestNetWriteWhileHandlerRuns(t *testing.T) {
+	peer, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpAddr := listener.Addr().String()
+	listener.Close()
+
+	// the peer only reads what the script writes once the script's server has answered it,
+	// so the write fills the socket buffers and must run the handler while it waits
+	received := make(chan int64, 1)
+	go func() {
+		conn, err := peer.Accept()
+		if err != nil {
+			received <- -1
+			return
+		}
+		defer conn.Close()
+		for {
+			response, err := http.Get("http://" + httpAddr + "/")
+			if err == nil {
+				response.Body.Close()
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		n, _ := io.Copy(ioutil.Discard, conn)
+		received <- n
+	}()
+
+	input := `import "net"; import "http";
+let s = http.serve("` + httpAddr + `", fn(req) { s.shutdown(); "ok" });
+let c = net.dial("` + peer.Addr().String() + `", 1000);
+let n = c.write("x".repeat(33554432));
+c.close();
+s.wait();
+n`
+	result := make(chan object.Object, 1)
+	go func() { result <- testEval(input) }()
+	select {
+	case evaluated := <-result:
+		testExpectedObject(t, evaluated, 33554432)
+	case <-time.After(10 * time.Second):
+		t.Fatal("write blocked the handler of the server")
+	}
+	if n := <-received; n != 33554432 {
+		t.Errorf("peer received %d bytes", n)
+	}
+}
+
+func TestNetModule(t *testing.T) {
+	// done closes the sockets of setup and returns its argument
+	setup := `import "net"; let l = net.listen("127.0.0.1:0"); let c = net.dial(l.addr, 1000); let s = l.accept(); let done = fn(result) { try(c.close); try(s.close); try(l.close); result }; `
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{setup + `c.write("ping"); done(s.read().decode())`, "ping"},
+		{setup + `done(str([c.write(b"abcdef"), s.read(4), s.read()]))`, `[6, b"abcd", b"ef"]`},
+		{setup + `s.write("one
+two
+"); done(str([c.readLine(), c.readLine()]))`, "[one, two]"},
+		{setup + `c.write("last"); c.close(); done(str([s.readLine(), s.readLine(), s.read()]))`, "[last, null, null]"},
+		{setup + `done(c.remoteAddr == l.addr)`, true},
+		{setup + `done(s.remoteAddr == c.localAddr)`, true},
+		{setup + `s.setReadDeadline(20); let (data, err) = try(s.read); done(r/^read: read tcp .*: i\/o timeout$/.match(err))`, true},
+		{setup + `s.setReadDeadline(20); s.setReadDeadline(null); c.write("x"); done(s.read().decode())`, "x"},
+		{setup + `done(try(s.setDeadline, "soon")[1])`, "argument to `setDeadline` must be INTEGER, DURATION or DATETIME, got STRING"},
+		{setup + `done(try(s.read, 0)[1])`, "argument to `read` must be positive, got 0"},
+		{setup + `done(try(s.read, 1048577)[1])`, "argument to `read` must be at most 1048576"},
+		{setup + `done(str([isinstance(l, net.Listener), isinstance(c, net.Connection), isinstance(s, net.Connection)]))`, "[true, true, true]"},
+		{`import "net"; let a = net.udp("127.0.0.1:0"); let b = net.udp("127.0.0.1:0"); a.send("hello", b.addr); let (data, from) = b.receive(); a.close(); b.close(); str([data, from == a.addr, isinstance(a, net.UDPSocket)])`, `[b"hello", true, true]`},
+		{`import "net"; let a = net.udp("127.0.0.1:0"); a.setReadDeadline(20); let (data, err) = try(a.receive); a.close(); r/i\/o timeout/.match(err)`, true},
+		{`import "net"; let a = net.udp("127.0.0.1:0"); let (data, err) = try(a.receive, 9223372036854775807); a.close(); err`, "argument to `receive` must be at most 1048576"},
+		{`import "net"; let l = net.listen("127.0.0.1:0"); l.close(); let (c, err) = try(l.accept); r/use of closed network connection/.match(err)`, true},
+		{`import "net"; let (c, err) = try(net.dial, "127.0.0.1:0", 100); r/^dial: dial tcp 127\.0\.0\.1:0: /.match(err)`, true},
+		{`import "net"; net.Connection()`, errorMessage("Connection objects cannot be created directly")},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
@@ -349,3 +1776,47 @@ func testNullObject(t *testing.T, obj object.Object) bool {
 	}
 	return true
 }
+
+func testErrorObject(t *testing.T, obj object.Object, expected string) bool {
+	errObj, ok := obj.(*object.Error)
+	if !ok {
+		t.Errorf("no error object returned. got=%T(%+v)", obj, obj)
+		return false
+	}
+	if errObj.Message != expected {
+		t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+		return false
+	}
+	return true
+}
+
+//errorMessage marks an expected value as the message of an error object
+type errorMessage string
+
+func testExpectedObject(t *testing.T, obj object.Object, expected interface{}) bool {
+	switch expected := expected.(type) {
+	case int:
+		return testIntegerObject(t, obj, int64(expected))
+	case bool:
+		return testBooleanObject(t, obj, expected)
+	case string:
+		return testStringObject(t, obj, expected)
+	case errorMessage:
+		return testErrorObject(t, obj, string(expected))
+	default:
+		return testNullObject(t, obj)
+	}
+}
+
+func testStringObject(t *testing.T, obj object.Object, expected string) bool {
+	result, ok := obj.(*object.String)
+	if !ok {
+		t.Errorf("object is not String. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%q, want=%q", result.Value, expected)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,327 @@
+package evaluator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"monkey/object"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+//Sandbox disables the builtins that let scripts run other programs, such as os.exec.
+// interpreters evaluating untrusted code should set it before running anything
+var Sandbox = false
+
+//processClass is the class of the Process objects returned by os.spawn
+var processClass = nativeClass("Process", nil)
+
+//execResult is the record returned by os.exec and by wait on a spawned process
+var execResult = &object.Record{Name: "ExecResult", Fields: []string{"stdout", "stderr", "exitCode"}}
+
+//pipeWaitDelay is how long a timed out or killed command's output is still read before its
+// pipes are closed, since a child it left running in the background may hold them open forever
+const pipeWaitDelay = 100 * time.Millisecond
+
+func init() {
+	// exec and spawn are part of the os module
+	osBuiltins["exec"] = &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			command, err := newCommand("exec", args)
+			if err != nil {
+				return err
+			}
+			defer command.cancel()
+			var stdout, stderr bytes.Buffer
+			command.Stdout, command.Stderr = &stdout, &stderr
+			return command.result(command.Run(), stdout.String(), stderr.String())
+		},
+	}
+	// a spawned process holds its pipes and the goroutines of its timeout until wait or kill
+	// is called, so scripts must call one of them for every process they spawn
+	osBuiltins["spawn"] = &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			command, err := newCommand("spawn", args)
+			if err != nil {
+				return err
+			}
+			process, err := startProcess(command)
+			if err != nil {
+				command.cancel()
+				return err
+			}
+			return process
+		},
+	}
+}
+
+//command a program to run along with the context enforcing its timeout
+type command struct {
+	*exec.Cmd
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+}
+
+//newCommand builds the command of exec(cmd, args, options) and spawn(cmd, args, options).
+// options is a hash with any of cwd, env (added to the current environment), stdin and
+// timeout in milliseconds. cancel must be called once the command is done
+func newCommand(builtin string, args []object.Object) (*command, *object.Error) {
+	if Sandbox {
+		return nil, newError("%s: running programs is disabled in sandboxed interpreters", builtin)
+	}
+	if err := checkArgCount(args, 1, 3); err != nil {
+		return nil, err
+	}
+	name, err := stringArg(builtin, args, 0)
+	if err != nil {
+		return nil, err
+	}
+	commandArgs := []string{}
+	if len(args) > 1 {
+		arr, err := arrayArg(builtin, args, 1)
+		if err != nil {
+			return nil, err
+		}
+		for _, element := range arr.Elements {
+			arg, ok := element.(*object.String)
+			if !ok {
+				return nil, newError("arguments of `%s` must be STRING, got %s", builtin, element.Type())
+			}
+			commandArgs = append(commandArgs, arg.Value)
+		}
+	}
+	options := object.NewHash()
+	if len(args) > 2 {
+		if options, err = hashArg(builtin, args, 2); err != nil {
+			return nil, err
+		}
+	}
+
+	cmd := &exec.Cmd{}
+	var timeout time.Duration
+	for _, pair := range options.Pairs() {
+		key, _ := pair.Key.(*object.String)
+		if key == nil {
+			return nil, newError("`%s` option names must be STRING, got %s", builtin, pair.Key.Type())
+		}
+		switch value := pair.Value.(type) {
+		case *object.String:
+			switch key.Value {
+			case "cwd":
+				cmd.Dir = value.Value
+				continue
+			case "stdin":
+				cmd.Stdin = strings.NewReader(value.Value)
+				continue
+			}
+		case *object.Integer:
+			if key.Value == "timeout" {
+				timeout = time.Duration(value.Value) * time.Millisecond
+				continue
+			}
+		case *object.Hash:
+			if key.Value == "env" {
+				cmd.Env = os.Environ()
+				for _, variable := range value.Pairs() {
+					name, ok := variable.Key.(*object.String)
+					setting, ok2 := variable.Value.(*object.String)
+					if !ok || !ok2 {
+						return nil, newError("`%s` env must map STRING to STRING", builtin)
+					}
+					cmd.Env = append(cmd.Env, name.Value+"="+setting.Value)
+				}
+				continue
+			}
+		}
+		return nil, newError("unknown or invalid `%s` option %s: %s", builtin, key.Value, pair.Value.Inspect())
+	}
+	// the context is also cancelled by kill, which stops the process and the wait for its pipes
+	ctx, cancel := context.WithCancel(context.Background())
+	if timeout > 0 {
+		cancel()
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
+	prepared := exec.CommandContext(ctx, name, commandArgs...)
+	prepared.Dir, prepared.Env, prepared.Stdin = cmd.Dir, cmd.Env, cmd.Stdin
+	if timeout > 0 {
+		prepared.WaitDelay = pipeWaitDelay
+	}
+	return &command{Cmd: prepared, ctx: ctx, cancel: cancel, timeout: timeout}, nil
+}
+
+//result turns the outcome of the finished command into an ExecResult.
+// exiting with a non zero status is a result, failing to run or timing out is an error
+func (c *command) result(err error, stdout, stderr string) object.Object {
+	if c.ctx.Err() == context.DeadlineExceeded {
+		return newError("%s timed out after %s", c.Args[0], c.timeout)
+	}
+	if c.ProcessState == nil {
+		var execErr *exec.Error
+		if errors.As(err, &execErr) {
+			err = execErr.Err
+		}
+		return newError("could not run %s: %s", c.Args[0], err)
+	}
+	return &object.RecordInstance{Record: execResult, Values: []object.Object{
+		&object.String{Value: stdout},
+		&object.String{Value: stderr},
+		&object.Integer{Value: int64(c.ProcessState.ExitCode())},
+	}}
+}
+
+//startProcess starts cmd and returns a Process object connected to its pipes.
+// its wait and kill methods reap the process and release its context
+func startProcess(cmd *command) (*object.ClassInstance, *object.Error) {
+	var stdin io.WriteCloser
+	var err error
+	if cmd.Stdin == nil {
+		if stdin, err = cmd.StdinPipe(); err != nil {
+			return nil, newError("spawn: %s", err)
+		}
+	}
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, newError("spawn: %s", err)
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, newError("spawn: %s", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, cmd.result(err, "", "").(*object.Error)
+	}
+	stdout, stderr := bufio.NewReader(stdoutPipe), bufio.NewReader(stderrPipe)
+	var result object.Object
+
+	closeStdin := func() {
+		if stdin != nil {
+			stdin.Close()
+			stdin = nil
+		}
+	}
+	// finish collects the output that was not read yet, reaps the process and releases its context
+	finish := func() object.Object {
+		if result != nil {
+			return result
+		}
+		closeStdin()
+		var restOfStdout, rest []byte
+		var waitErr error
+		whileBlocked(func() {
+			read := make(chan struct{})
+			defer close(read)
+			go func() {
+				select {
+				case <-cmd.ctx.Done():
+				case <-read:
+					return
+				}
+				select {
+				case <-read:
+				case <-time.After(pipeWaitDelay):
+					stdoutPipe.Close()
+					stderrPipe.Close()
+				}
+			}()
+			restOfStderr := make(chan []byte)
+			go func() {
+				rest, _ := ioutil.ReadAll(stderr)
+				restOfStderr <- rest
+			}()
+			restOfStdout, _ = ioutil.ReadAll(stdout)
+			rest = <-restOfStderr
+			waitErr = cmd.Wait()
+		})
+		result = cmd.result(waitErr, string(restOfStdout), string(rest))
+		cmd.cancel()
+		return result
+	}
+	return newNativeInstance(processClass, map[string]object.Object{
+		"pid": &object.Integer{Value: int64(cmd.Process.Pid)},
+		"write": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 1, 1); err != nil {
+					return err
+				}
+				text, err := stringArg("write", args, 0)
+				if err != nil {
+					return err
+				}
+				if stdin == nil {
+					return newError("write: standard input of %s is closed", cmd.Args[0])
+				}
+				n, writeErr := io.WriteString(stdin, text)
+				if writeErr != nil {
+					return newError("write: %s", writeErr)
+				}
+				return &object.Integer{Value: int64(n)}
+			},
+		},
+		"closeStdin": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				closeStdin()
+				return NULL
+			},
+		},
+		// reading one stream while the process blocks writing to the other one waits forever,
+		// so programs that write a lot to both should be run with exec
+		"readLine": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				return readPipeLine(stdout)
+			},
+		},
+		"readErrLine": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				return readPipeLine(stderr)
+			},
+		},
+		// wait closes standard input, waits for the process to exit and returns its
+		// ExecResult with whatever output was not read yet
+		"wait": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				return finish()
+			},
+		},
+		// kill stops the process and reaps it, after which wait returns its ExecResult
+		"kill": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				if result == nil {
+					// cancelling the context kills the process and stops waiting for its pipes
+					cmd.cancel()
+					finish()
+				}
+				return NULL
+			},
+		},
+	}), nil
+}
+
+//readPipeLine returns the next line of a process' output, or null once it is closed
+func readPipeLine(reader *bufio.Reader) object.Object {
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return NULL
+	}
+	return &object.String{Value: strings.TrimSuffix(line, "\n")}
+}
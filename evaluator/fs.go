@@ -0,0 +1,265 @@
+package evaluator
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"monkey/object"
+	"os"
+	"strings"
+)
+
+//fileClass is the class of the File objects returned by fs.open
+var fileClass = nativeClass("File", nil)
+
+func init() {
+	registerModule("fs", func() *object.Module {
+		members := map[string]object.Object{"File": fileClass}
+		for name, builtin := range fsBuiltins {
+			members[name] = builtin
+		}
+		return newModule("fs", members)
+	})
+}
+
+//fsBuiltins are the functions of the fs module. failures are returned as errors
+// naming the function, which try() turns into values
+var fsBuiltins = map[string]*object.Builtin{
+	"readFile": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			path, err := singlePathArg("readFile", args)
+			if err != nil {
+				return err
+			}
+			content, readErr := ioutil.ReadFile(path)
+			if readErr != nil {
+				return fsError("readFile", readErr)
+			}
+			return &object.String{Value: string(content)}
+		},
+	},
+	"writeFile": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return writeToFile("writeFile", args, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+		},
+	},
+	"appendFile": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return writeToFile("appendFile", args, os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+		},
+	},
+	"exists": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			path, err := singlePathArg("exists", args)
+			if err != nil {
+				return err
+			}
+			_, statErr := os.Stat(path)
+			return nativeBoolToBooleanObject(statErr == nil)
+		},
+	},
+	"listDir": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			path, err := singlePathArg("listDir", args)
+			if err != nil {
+				return err
+			}
+			entries, readErr := ioutil.ReadDir(path)
+			if readErr != nil {
+				return fsError("listDir", readErr)
+			}
+			names := make([]string, len(entries))
+			for i, entry := range entries {
+				names[i] = entry.Name()
+			}
+			return stringsToArray(names)
+		},
+	},
+	"mkdir": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			path, err := singlePathArg("mkdir", args)
+			if err != nil {
+				return err
+			}
+			// missing parents are created too, and an existing directory is not an error
+			if mkdirErr := os.MkdirAll(path, 0755); mkdirErr != nil {
+				return fsError("mkdir", mkdirErr)
+			}
+			return NULL
+		},
+	},
+	"remove": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			path, err := stringArg("remove", args, 0)
+			if err != nil {
+				return err
+			}
+			remove := os.Remove
+			if len(args) == 2 && isTruthy(args[1]) {
+				remove = os.RemoveAll
+			}
+			if removeErr := remove(path); removeErr != nil {
+				return fsError("remove", removeErr)
+			}
+			return NULL
+		},
+	},
+	"stat": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			path, err := singlePathArg("stat", args)
+			if err != nil {
+				return err
+			}
+			info, statErr := os.Stat(path)
+			if statErr != nil {
+				return fsError("stat", statErr)
+			}
+			stat := object.NewHash()
+			stat.Set(&object.String{Value: "name"}, &object.String{Value: info.Name()})
+			stat.Set(&object.String{Value: "size"}, &object.Integer{Value: info.Size()})
+			stat.Set(&object.String{Value: "isDir"}, nativeBoolToBooleanObject(info.IsDir()))
+			stat.Set(&object.String{Value: "mode"}, &object.Integer{Value: int64(info.Mode().Perm())})
+			stat.Set(&object.String{Value: "modified"}, &object.Integer{Value: info.ModTime().Unix()})
+			return stat
+		},
+	},
+	"open": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			path, err := stringArg("open", args, 0)
+			if err != nil {
+				return err
+			}
+			mode := "r"
+			if len(args) == 2 {
+				if mode, err = stringArg("open", args, 1); err != nil {
+					return err
+				}
+			}
+			flags, ok := fileModes[mode]
+			if !ok {
+				return newError("unknown file mode %q, want one of r, w or a", mode)
+			}
+			file, openErr := os.OpenFile(path, flags, 0644)
+			if openErr != nil {
+				return fsError("open", openErr)
+			}
+			return newFileHandle(file)
+		},
+	},
+}
+
+//fileModes maps the modes of fs.open to the flags the file is opened with
+var fileModes = map[string]int{
+	"r": os.O_RDONLY,
+	"w": os.O_WRONLY | os.O_CREATE | os.O_TRUNC,
+	"a": os.O_WRONLY | os.O_CREATE | os.O_APPEND,
+}
+
+//newFileHandle returns the File object of an open file with readLine, write and close
+func newFileHandle(file *os.File) *object.ClassInstance {
+	reader := bufio.NewReader(file)
+	closed := false
+	return newNativeInstance(fileClass, map[string]object.Object{
+		"path": &object.String{Value: file.Name()},
+		// readLine returns the next line without its line ending, or null at the end of the file
+		"readLine": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				if closed {
+					return newError("readLine: file %s is closed", file.Name())
+				}
+				line, err := reader.ReadString('\n')
+				if err == io.EOF && line == "" {
+					return NULL
+				}
+				if err != nil && err != io.EOF {
+					return fsError("readLine", err)
+				}
+				line = strings.TrimSuffix(line, "\n")
+				return &object.String{Value: strings.TrimSuffix(line, "\r")}
+			},
+		},
+		"write": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 1, 1); err != nil {
+					return err
+				}
+				text, err := stringArg("write", args, 0)
+				if err != nil {
+					return err
+				}
+				if closed {
+					return newError("write: file %s is closed", file.Name())
+				}
+				n, writeErr := file.WriteString(text)
+				if writeErr != nil {
+					return fsError("write", writeErr)
+				}
+				return &object.Integer{Value: int64(n)}
+			},
+		},
+		"close": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				if closed {
+					return NULL
+				}
+				closed = true
+				if err := file.Close(); err != nil {
+					return fsError("close", err)
+				}
+				return NULL
+			},
+		},
+	})
+}
+
+//singlePathArg validates builtins that take only a path
+func singlePathArg(builtin string, args []object.Object) (string, *object.Error) {
+	if err := checkArgCount(args, 1, 1); err != nil {
+		return "", err
+	}
+	return stringArg(builtin, args, 0)
+}
+
+//writeToFile implements writeFile and appendFile, which differ only in how the file is opened
+func writeToFile(builtin string, args []object.Object, flags int) object.Object {
+	if err := checkArgCount(args, 2, 2); err != nil {
+		return err
+	}
+	path, err := stringArg(builtin, args, 0)
+	if err != nil {
+		return err
+	}
+	text, err := stringArg(builtin, args, 1)
+	if err != nil {
+		return err
+	}
+	file, openErr := os.OpenFile(path, flags, 0644)
+	if openErr != nil {
+		return fsError(builtin, openErr)
+	}
+	_, writeErr := file.WriteString(text)
+	if closeErr := file.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		return fsError(builtin, writeErr)
+	}
+	return NULL
+}
+
+//fsError turns an error of the os package into a Monkey error
+func fsError(builtin string, err error) *object.Error {
+	return newError("%s: %s", builtin, err)
+}
@@ -0,0 +1,154 @@
+package evaluator
+
+import (
+	"monkey/object"
+)
+
+//hashBuiltins are available both as builtins taking the hash as their first
+// argument and as methods on hashes. eg. keys(h) or h.keys()
+// results follow the insertion order of the hash
+var hashBuiltins = map[string]*object.Builtin{
+	"keys": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			hash, err := singleHashArg("keys", args)
+			if err != nil {
+				return err
+			}
+			pairs := hash.Pairs()
+			elements := make([]object.Object, len(pairs))
+			for i, pair := range pairs {
+				elements[i] = pair.Key
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"values": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			hash, err := singleHashArg("values", args)
+			if err != nil {
+				return err
+			}
+			pairs := hash.Pairs()
+			elements := make([]object.Object, len(pairs))
+			for i, pair := range pairs {
+				elements[i] = pair.Value
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"items": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			hash, err := singleHashArg("items", args)
+			if err != nil {
+				return err
+			}
+			pairs := hash.Pairs()
+			elements := make([]object.Object, len(pairs))
+			for i, pair := range pairs {
+				elements[i] = &object.Array{Elements: []object.Object{pair.Key, pair.Value}}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"has": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 2); err != nil {
+				return err
+			}
+			hash, key, err := hashAndKeyArgs("has", args)
+			if err != nil {
+				return err
+			}
+			_, ok := hash.Get(key)
+			return nativeBoolToBooleanObject(ok)
+		},
+	},
+	"get": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 3); err != nil {
+				return err
+			}
+			hash, key, err := hashAndKeyArgs("get", args)
+			if err != nil {
+				return err
+			}
+			if value, ok := hash.Get(key); ok {
+				return value
+			}
+			if len(args) == 3 {
+				return args[2]
+			}
+			return NULL
+		},
+	},
+	"delete": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 2); err != nil {
+				return err
+			}
+			hash, key, err := hashAndKeyArgs("delete", args)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(hash.Delete(key))
+		},
+	},
+	"merge": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			merged := object.NewHash()
+			for i := range args {
+				hash, err := hashArg("merge", args, i)
+				if err != nil {
+					return err
+				}
+				for _, pair := range hash.Pairs() {
+					merged.Set(pair.Key.(object.Hashable), pair.Value)
+				}
+			}
+			return merged
+		},
+	},
+	"len": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			hash, err := singleHashArg("len", args)
+			if err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(hash.Len())}
+		},
+	},
+}
+
+func init() {
+	registerBuiltinMethods(object.HASH_OBJ, hashBuiltins)
+}
+
+//hashArg returns the argument at index i, which must be a Hash
+func hashArg(builtin string, args []object.Object, i int) (*object.Hash, *object.Error) {
+	hash, ok := args[i].(*object.Hash)
+	if !ok {
+		return nil, newError("argument %d to `%s` must be HASH, got %s", i+1, builtin, args[i].Type())
+	}
+	return hash, nil
+}
+
+//singleHashArg validates builtins that take only a hash
+func singleHashArg(builtin string, args []object.Object) (*object.Hash, *object.Error) {
+	if err := checkArgCount(args, 1, 1); err != nil {
+		return nil, err
+	}
+	return hashArg(builtin, args, 0)
+}
+
+//hashAndKeyArgs returns the hash and the key of builtins called as fn(hash, key, ...)
+func hashAndKeyArgs(builtin string, args []object.Object) (*object.Hash, object.Hashable, *object.Error) {
+	hash, err := hashArg(builtin, args, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, ok := object.AsHashKey(args[1])
+	if !ok {
+		return nil, nil, newError("unusable as hash key: %s", args[1].Type())
+	}
+	return hash, key, nil
+}
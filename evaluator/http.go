@@ -0,0 +1,227 @@
+package evaluator
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"monkey/object"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//httpTimeout is how long a request may take unless its timeout option says otherwise,
+// replaceable in tests
+var httpTimeout = 30 * time.Second
+
+//maxHTTPBodySize is the largest body, in bytes, read from a response or a request to a
+// server. larger requests are answered with status 413. replaceable in tests
+var maxHTTPBodySize int64 = 10 << 20
+
+//responseClass is the class of the Response objects returned by requests
+var responseClass = nativeClass("Response", nil)
+
+func init() {
+	registerModule("http", func() *object.Module {
+		members := map[string]object.Object{
+			"Response": responseClass,
+			"Server":   serverClass,
+			"Request":  requestClass,
+			"Router":   routerClass,
+		}
+		for name, builtin := range httpBuiltins {
+			members[name] = builtin
+		}
+		return newModule("http", members)
+	})
+}
+
+//httpBuiltins are the functions of the http module. options are a hash with any of
+// headers (a hash), body (a string, bytes, or a hash or array sent as JSON) and timeout
+// in milliseconds, 30 seconds by default and unlimited when 0. every call returns a
+// Response whatever its status
+var httpBuiltins = map[string]*object.Builtin{
+	// get(url, options)
+	"get": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			return sendRequest("get", "GET", args[0], NULL, args[1:])
+		},
+	},
+	// post(url, body, options)
+	"post": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 3); err != nil {
+				return err
+			}
+			return sendRequest("post", "POST", args[0], args[1], args[2:])
+		},
+	},
+	// request({"method": "PUT", "url": url, ...options})
+	"request": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			options, err := hashArg("request", args, 0)
+			if err != nil {
+				return err
+			}
+			method, _ := options.Get(&object.String{Value: "method"})
+			if method == nil {
+				method = &object.String{Value: "GET"}
+			}
+			name, ok := method.(*object.String)
+			if !ok {
+				return newError("`request` method must be STRING, got %s", method.Type())
+			}
+			url, _ := options.Get(&object.String{Value: "url"})
+			if url == nil {
+				return newError("`request` needs a url")
+			}
+			body, _ := options.Get(&object.String{Value: "body"})
+			if body == nil {
+				body = NULL
+			}
+			return sendRequest("request", strings.ToUpper(name.Value), url, body, args)
+		},
+	},
+}
+
+//sendRequest sends an HTTP request and returns its Response.
+// options holds at most one hash of options; method, url and body are not read from it
+func sendRequest(builtin, method string, url, body object.Object, options []object.Object) object.Object {
+	target, ok := url.(*object.String)
+	if !ok {
+		return newError("`%s` url must be STRING, got %s", builtin, url.Type())
+	}
+	settings := object.NewHash()
+	if len(options) == 1 {
+		var err *object.Error
+		if settings, err = hashArg(builtin, options, 0); err != nil {
+			return err
+		}
+	}
+
+	data, contentType, bodyErr := encodeHTTPBody(builtin, body)
+	if bodyErr != nil {
+		return bodyErr
+	}
+	var content io.Reader
+	if data != nil {
+		content = bytes.NewReader(data)
+	}
+	request, err := http.NewRequest(method, target.Value, content)
+	if err != nil {
+		return newError("%s: %s", builtin, err)
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	client := &http.Client{Timeout: httpTimeout}
+	for _, pair := range settings.Pairs() {
+		key, _ := pair.Key.(*object.String)
+		if key == nil {
+			return newError("`%s` option names must be STRING, got %s", builtin, pair.Key.Type())
+		}
+		switch key.Value {
+		case "method", "url", "body":
+			// read by the caller
+		case "headers":
+			headers, ok := pair.Value.(*object.Hash)
+			if !ok {
+				return newError("`%s` headers must be HASH, got %s", builtin, pair.Value.Type())
+			}
+			for _, header := range headers.Pairs() {
+				name, ok := header.Key.(*object.String)
+				value, ok2 := header.Value.(*object.String)
+				if !ok || !ok2 {
+					return newError("`%s` headers must map STRING to STRING", builtin)
+				}
+				request.Header.Set(name.Value, value.Value)
+			}
+		case "timeout":
+			timeout, ok := pair.Value.(*object.Integer)
+			if !ok {
+				return newError("`%s` timeout must be INTEGER, got %s", builtin, pair.Value.Type())
+			}
+			if timeout.Value < 0 {
+				return newError("`%s` timeout must not be negative", builtin)
+			}
+			client.Timeout = time.Duration(timeout.Value) * time.Millisecond
+		default:
+			return newError("unknown `%s` option %s", builtin, key.Value)
+		}
+	}
+
+	// the script may be requesting one of its own servers, whose handlers run meanwhile
+	var response *http.Response
+	var readErr error
+	whileBlocked(func() {
+		if response, err = client.Do(request); err == nil {
+			defer response.Body.Close()
+			data, readErr = ioutil.ReadAll(io.LimitReader(response.Body, maxHTTPBodySize+1))
+		}
+	})
+	if err != nil {
+		return newError("%s: %s", builtin, err)
+	}
+	if readErr != nil {
+		return newError("%s: reading the response: %s", builtin, readErr)
+	}
+	if int64(len(data)) > maxHTTPBodySize {
+		return newError("%s: response body is larger than %d bytes", builtin, maxHTTPBodySize)
+	}
+	return newHTTPResponse(response, data)
+}
+
+//encodeHTTPBody returns the data of a request or response body and, for JSON, its content
+// type. strings and bytes are sent as they are, hashes and arrays as JSON and null means
+// no body
+func encodeHTTPBody(builtin string, body object.Object) ([]byte, string, *object.Error) {
+	switch body := body.(type) {
+	case *object.Null:
+		return nil, "", nil
+	case *object.String:
+		return []byte(body.Value), "", nil
+	case *object.Bytes:
+		return body.Value, "", nil
+	case *object.Hash, *object.Array:
+		encoded := jsonEncode(body)
+		if err, ok := encoded.(*object.Error); ok {
+			return nil, "", err
+		}
+		return []byte(encoded.(*object.String).Value), "application/json", nil
+	default:
+		return nil, "", newError("`%s` body must be STRING, BYTES, HASH or ARRAY, got %s", builtin, body.Type())
+	}
+}
+
+//newHTTPResponse returns the Response object of a request. headers with several values
+// are joined with commas
+func newHTTPResponse(response *http.Response, data []byte) *object.ClassInstance {
+	headers := object.NewHash()
+	for name, values := range response.Header {
+		headers.Set(&object.String{Value: name}, &object.String{Value: strings.Join(values, ", ")})
+	}
+	body := &object.String{Value: string(data)}
+	return newNativeInstance(responseClass, map[string]object.Object{
+		"status":  &object.Integer{Value: int64(response.StatusCode)},
+		"url":     &object.String{Value: response.Request.URL.String()},
+		"headers": headers,
+		"body":    body,
+		"bytes":   &object.Bytes{Value: data},
+		// json decodes the body
+		"json": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				return jsonDecode(body)
+			},
+		},
+	})
+}
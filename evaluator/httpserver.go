@@ -0,0 +1,436 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"monkey/object"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+)
+
+//handlerCall is a call of an http handler waiting to be run by the interpreter
+type handlerCall struct {
+	handler object.Object
+	args    []object.Object
+	result  object.Object
+	done    chan struct{}
+}
+
+//handlerCalls carries the calls of http handlers to the goroutine running the script.
+// the evaluator is not safe for concurrent use, so handlers only run while the script
+// is blocked in a builtin waiting through whileBlocked, such as a server's `wait` or an
+// http request, and a script that never blocks leaves its requests waiting
+var handlerCalls = make(chan *handlerCall)
+
+//whileBlocked runs wait on another goroutine and meanwhile runs the calls of http
+// handlers on the current one. wait must not evaluate anything itself
+func whileBlocked(wait func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait()
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		case call := <-handlerCalls:
+			call.run()
+		}
+	}
+}
+
+func (call *handlerCall) run() {
+	defer close(call.done)
+	call.result = applyFunction(call.handler, call.args)
+}
+
+//callHandler hands a call of handler to the interpreter and waits for its result.
+// it gives up when ctx is done before the interpreter picks the call up
+func callHandler(ctx context.Context, handler object.Object, args []object.Object) (object.Object, bool) {
+	call := &handlerCall{handler: handler, args: args, done: make(chan struct{})}
+	select {
+	case handlerCalls <- call:
+	case <-ctx.Done():
+		return nil, false
+	}
+	<-call.done
+	return call.result, true
+}
+
+//serverClass, requestClass and routerClass are the classes of the objects of http servers.
+// routerClass is set in init since its constructor refers to it
+var (
+	serverClass  = nativeClass("Server", nil)
+	requestClass = nativeClass("Request", nil)
+	routerClass  *object.Class
+)
+
+//shutdownTimeout is how long a graceful shutdown waits for requests in progress
+const shutdownTimeout = 5 * time.Second
+
+func init() {
+	// serve(addr, handler) starts serving HTTP on addr in the background and returns
+	// the Server. handler is a function or an object with a handle method, such as a
+	// Router, called with a Request and returning a response (see writeHTTPResponse).
+	// eg. http.serve(":8080", fn(req) { "hello " + req.path }).wait()
+	httpBuiltins["serve"] = &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 2); err != nil {
+				return err
+			}
+			addr, err := stringArg("serve", args, 0)
+			if err != nil {
+				return err
+			}
+			handler := args[1]
+			if instance, ok := handler.(*object.ClassInstance); ok {
+				handler = evalDotExpression(instance, "handle", object.NewEnvironment())
+				if isError(handler) {
+					return handler
+				}
+			}
+			switch handler.(type) {
+			case *object.Function, *object.Builtin, *object.BoundMethod:
+			default:
+				return newError("argument 2 to `serve` must be FUNCTION or an object with a handle method, got %s", args[1].Type())
+			}
+			return startServer(addr, handler)
+		},
+	}
+	// Router() returns a router dispatching requests on their method and path
+	routerClass = nativeClass("Router", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 0, 0); err != nil {
+				return err
+			}
+			return newRouter()
+		},
+	})
+}
+
+//httpServer is the state behind a Server object
+type httpServer struct {
+	server *http.Server
+	once   sync.Once
+	done   chan struct{}
+	err    error
+}
+
+//shutdown stops accepting connections and closes the server once the requests in
+// progress are answered. it does not wait, so handlers may call it
+func (s *httpServer) shutdown() {
+	s.once.Do(func() {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			if err := s.server.Shutdown(ctx); err != nil {
+				s.server.Close()
+			}
+			close(s.done)
+		}()
+	})
+}
+
+//startServer listens on addr and serves requests with handler until shut down
+func startServer(addr string, handler object.Object) object.Object {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return newError("serve: %s", err)
+	}
+	s := &httpServer{done: make(chan struct{})}
+	s.server = &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxHTTPBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "reading the request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		response, ok := callHandler(r.Context(), handler, []object.Object{newHTTPRequest(r, data)})
+		if !ok {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		writeHTTPResponse(w, response)
+	})}
+	go func() {
+		if err := s.server.Serve(listener); err != http.ErrServerClosed {
+			s.err = err
+			s.shutdown()
+		}
+	}()
+
+	return newNativeInstance(serverClass, map[string]object.Object{
+		// addr is the address served, with the port chosen when addr asked for port 0
+		"addr": &object.String{Value: listener.Addr().String()},
+		// wait handles requests until the server is shut down, shutting it down
+		// gracefully when the interpreter is interrupted
+		"wait": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				interrupt := make(chan os.Signal, 1)
+				signal.Notify(interrupt, os.Interrupt)
+				defer signal.Stop(interrupt)
+				whileBlocked(func() {
+					select {
+					case <-s.done:
+					case <-interrupt:
+						s.shutdown()
+						<-s.done
+					}
+				})
+				if s.err != nil {
+					return newError("serve: %s", s.err)
+				}
+				return NULL
+			},
+		},
+		"shutdown": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				s.shutdown()
+				return NULL
+			},
+		},
+	})
+}
+
+//newHTTPRequest returns the Request object handlers receive: method, path, query (a hash
+// of the first value of each parameter), headers, body, params (filled in by routers)
+// and json() decoding the body, whose data was already read
+func newHTTPRequest(r *http.Request, data []byte) *object.ClassInstance {
+	query := object.NewHash()
+	for name, values := range r.URL.Query() {
+		query.Set(&object.String{Value: name}, &object.String{Value: values[0]})
+	}
+	headers := object.NewHash()
+	for name, values := range r.Header {
+		headers.Set(&object.String{Value: name}, &object.String{Value: strings.Join(values, ", ")})
+	}
+	body := &object.String{Value: string(data)}
+	return newNativeInstance(requestClass, map[string]object.Object{
+		"method":  &object.String{Value: r.Method},
+		"path":    &object.String{Value: r.URL.Path},
+		"query":   query,
+		"headers": headers,
+		"body":    body,
+		"bytes":   &object.Bytes{Value: data},
+		"params":  object.NewHash(),
+		"json": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				return jsonDecode(body)
+			},
+		},
+	})
+}
+
+//writeHTTPResponse answers with what a handler returned: a hash with any of status
+// (200 by default), headers and body, or just a body (see encodeHTTPBody). errors
+// are answered with status 500 and their message
+func writeHTTPResponse(w http.ResponseWriter, response object.Object) {
+	status := http.StatusOK
+	body := response
+	headers := object.NewHash()
+	switch response := response.(type) {
+	case *object.Error:
+		http.Error(w, response.Message, http.StatusInternalServerError)
+		return
+	case *object.Hash:
+		body = NULL
+		for _, pair := range response.Pairs() {
+			key, _ := pair.Key.(*object.String)
+			if key == nil {
+				http.Error(w, "response keys must be STRING, got "+string(pair.Key.Type()), http.StatusInternalServerError)
+				return
+			}
+			switch key.Value {
+			case "status":
+				code, ok := pair.Value.(*object.Integer)
+				if !ok || code.Value < 100 || code.Value > 999 {
+					http.Error(w, "invalid response status "+pair.Value.Inspect(), http.StatusInternalServerError)
+					return
+				}
+				status = int(code.Value)
+			case "headers":
+				hash, ok := pair.Value.(*object.Hash)
+				if !ok {
+					http.Error(w, "response headers must be HASH, got "+string(pair.Value.Type()), http.StatusInternalServerError)
+					return
+				}
+				headers = hash
+			case "body":
+				body = pair.Value
+			default:
+				http.Error(w, "unknown response key "+key.Value, http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+	data, contentType, err := encodeHTTPBody("serve", body)
+	if err != nil {
+		http.Error(w, err.Message, http.StatusInternalServerError)
+		return
+	}
+	switch body.(type) {
+	case *object.String:
+		contentType = "text/plain; charset=utf-8"
+	case *object.Bytes:
+		contentType = "application/octet-stream"
+	}
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	for _, pair := range headers.Pairs() {
+		name, ok := pair.Key.(*object.String)
+		value, ok2 := pair.Value.(*object.String)
+		if !ok || !ok2 {
+			http.Error(w, "response headers must map STRING to STRING", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set(name.Value, value.Value)
+	}
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+//route is a handler registered with a router. pattern segments starting with : match
+// any segment and are passed to the handler in request.params
+type route struct {
+	method   string
+	segments []string
+	handler  object.Object
+}
+
+//match returns the parameters of path when it matches the route's pattern
+func (r *route) match(path []string) (map[string]string, bool) {
+	if len(path) != len(r.segments) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, segment := range r.segments {
+		if strings.HasPrefix(segment, ":") {
+			params[segment[1:]] = path[i]
+		} else if segment != path[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+//splitPath returns the segments of a path, ignoring leading and trailing slashes
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, "/")
+}
+
+//newRouter returns a Router. route(method, pattern, handler) registers a handler, and get,
+// post, put and delete are shortcuts for those methods. handle(request) calls the first
+// handler matching the request, answering 404 when no pattern matches and 405 when only
+// the method differs. eg. router.get("/users/:id", fn(req) { req.params["id"] })
+func newRouter() *object.ClassInstance {
+	routes := []*route{}
+	router := newNativeInstance(routerClass, map[string]object.Object{})
+	add := func(builtin, method string, args []object.Object) object.Object {
+		pattern, err := stringArg(builtin, args, 0)
+		if err != nil {
+			return err
+		}
+		switch args[1].(type) {
+		case *object.Function, *object.Builtin, *object.BoundMethod:
+		default:
+			return newError("argument 2 to `%s` must be FUNCTION, got %s", builtin, args[1].Type())
+		}
+		routes = append(routes, &route{method: strings.ToUpper(method), segments: splitPath(pattern), handler: args[1]})
+		return router
+	}
+	methods := map[string]object.Object{
+		"route": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 3, 3); err != nil {
+					return err
+				}
+				method, err := stringArg("route", args, 0)
+				if err != nil {
+					return err
+				}
+				return add("route", method, args[1:])
+			},
+		},
+		"handle": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 1, 1); err != nil {
+					return err
+				}
+				request, ok := args[0].(*object.ClassInstance)
+				if !ok || request.Class != requestClass {
+					return newError("argument to `handle` must be a Request, got %s", args[0].Type())
+				}
+				method, _ := request.Env.Get("method")
+				path, _ := request.Env.Get("path")
+				segments := splitPath(path.(*object.String).Value)
+				allowed := false
+				for _, r := range routes {
+					params, ok := r.match(segments)
+					if !ok {
+						continue
+					}
+					if r.method != method.(*object.String).Value {
+						allowed = true
+						continue
+					}
+					hash := object.NewHash()
+					for name, value := range params {
+						hash.Set(&object.String{Value: name}, &object.String{Value: value})
+					}
+					request.Env.Set("params", hash)
+					return applyFunction(r.handler, []object.Object{request})
+				}
+				if allowed {
+					return textResponse(http.StatusMethodNotAllowed)
+				}
+				return textResponse(http.StatusNotFound)
+			},
+		},
+	}
+	for _, method := range []string{"get", "post", "put", "delete"} {
+		method := method
+		methods[method] = &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 2, 2); err != nil {
+					return err
+				}
+				return add(method, method, args)
+			},
+		}
+	}
+	router.Env.SetMultiple(methods)
+	return router
+}
+
+//textResponse returns a response hash with status and its standard text as body
+func textResponse(status int) *object.Hash {
+	response := object.NewHash()
+	response.Set(&object.String{Value: "status"}, &object.Integer{Value: int64(status)})
+	response.Set(&object.String{Value: "body"}, &object.String{Value: http.StatusText(status)})
+	return response
+}
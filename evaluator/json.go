@@ -0,0 +1,286 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"monkey/object"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	registerModule("json", func() *object.Module {
+		return newModule("json", map[string]object.Object{
+			"encode": &object.Builtin{Fn: jsonEncode},
+			"decode": &object.Builtin{Fn: jsonDecode},
+		})
+	})
+}
+
+//maxJSONIndent is the most spaces json.encode indents with
+const maxJSONIndent = 16
+
+//jsonEncode implements json.encode(value, indent). without an indent the output is compact,
+// indent is either a number of spaces, at most maxJSONIndent, or the string to indent with
+func jsonEncode(args ...object.Object) object.Object {
+	if err := checkArgCount(args, 1, 2); err != nil {
+		return err
+	}
+	encoder := &jsonEncoder{}
+	if err := encoder.encode(args[0]); err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		return &object.String{Value: encoder.buf.String()}
+	}
+	var indent string
+	switch arg := args[1].(type) {
+	case *object.Integer:
+		if arg.Value < 0 || arg.Value > maxJSONIndent {
+			return newError("indent of `encode` must be between 0 and %d, got %d", maxJSONIndent, arg.Value)
+		}
+		indent = strings.Repeat(" ", int(arg.Value))
+	case *object.String:
+		indent = arg.Value
+	default:
+		return newError("argument 2 to `encode` must be INTEGER or STRING, got %s", arg.Type())
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, encoder.buf.Bytes(), "", indent); err != nil {
+		return newError("encode: %s", err)
+	}
+	return &object.String{Value: out.String()}
+}
+
+//jsonEncoder writes compact JSON for Monkey values.
+// path holds the arrays and hashes being encoded so cycles are reported instead of recursing forever
+type jsonEncoder struct {
+	buf  bytes.Buffer
+	path []object.Object
+}
+
+func (e *jsonEncoder) encode(obj object.Object) *object.Error {
+	switch obj := obj.(type) {
+	case *object.Null:
+		e.buf.WriteString("null")
+	case *object.Boolean:
+		e.buf.WriteString(strconv.FormatBool(obj.Value))
+	case *object.Integer, *object.BigInt, *object.Decimal:
+		e.buf.WriteString(obj.Inspect())
+	case *object.Float:
+		if math.IsNaN(obj.Value) || math.IsInf(obj.Value, 0) {
+			return newError("cannot encode %s as JSON", obj.Inspect())
+		}
+		e.buf.WriteString(obj.Inspect())
+	case *object.String:
+		e.encodeString(obj.Value)
+	case *object.Array:
+		return e.encodeArray(obj, obj.Elements)
+	case *object.Tuple:
+		return e.encodeArray(obj, obj.Elements)
+	case *object.Hash:
+		return e.encodeHash(obj)
+	case *object.RecordInstance:
+		hash := object.NewHash()
+		for i, field := range obj.Record.Fields {
+			hash.Set(&object.String{Value: field}, obj.Values[i])
+		}
+		return e.encodeHash(hash)
+	case *object.ClassInstance:
+		hook := evalDotExpression(obj, "__json__", obj.Env)
+		if isError(hook) {
+			return newError("cannot encode %s as JSON: it has no __json__ method", obj.Inspect())
+		}
+		// the instance is on the path while its __json__ value is encoded, so a value
+		// leading back to it is reported as a cycle
+		if err := e.enter(obj); err != nil {
+			return err
+		}
+		value := applyFunction(hook, []object.Object{})
+		if err, ok := value.(*object.Error); ok {
+			return err
+		}
+		if _, ok := value.(*object.ClassInstance); ok {
+			return newError("__json__ of %s must not return an instance", obj.Name)
+		}
+		if err := e.encode(value); err != nil {
+			return err
+		}
+		e.leave()
+	default:
+		return newError("cannot encode %s as JSON", obj.Type())
+	}
+	return nil
+}
+
+func (e *jsonEncoder) encodeString(s string) {
+	encoder := json.NewEncoder(&e.buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	// Encode terminates every value with a newline
+	e.buf.Truncate(e.buf.Len() - 1)
+}
+
+func (e *jsonEncoder) encodeArray(container object.Object, elements []object.Object) *object.Error {
+	if err := e.enter(container); err != nil {
+		return err
+	}
+	e.buf.WriteByte('[')
+	for i, element := range elements {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		if err := e.encode(element); err != nil {
+			return err
+		}
+	}
+	e.buf.WriteByte(']')
+	e.leave()
+	return nil
+}
+
+func (e *jsonEncoder) encodeHash(hash *object.Hash) *object.Error {
+	if err := e.enter(hash); err != nil {
+		return err
+	}
+	e.buf.WriteByte('{')
+	for i, pair := range hash.Pairs() {
+		key, ok := pair.Key.(*object.String)
+		if !ok {
+			return newError("JSON object keys must be STRING, got %s", pair.Key.Type())
+		}
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		e.encodeString(key.Value)
+		e.buf.WriteByte(':')
+		if err := e.encode(pair.Value); err != nil {
+			return err
+		}
+	}
+	e.buf.WriteByte('}')
+	e.leave()
+	return nil
+}
+
+//enter pushes a container onto the path, failing if it is already being encoded
+func (e *jsonEncoder) enter(container object.Object) *object.Error {
+	for _, outer := range e.path {
+		if outer == container {
+			return newError("cannot encode a cyclic %s as JSON", container.Type())
+		}
+	}
+	e.path = append(e.path, container)
+	return nil
+}
+
+//leave removes the innermost container from the path once it is encoded
+func (e *jsonEncoder) leave() {
+	e.path = e.path[:len(e.path)-1]
+}
+
+//jsonDecode implements json.decode(text). objects become hashes in the order of their keys,
+// numbers without a fraction or exponent become integers and everything else maps directly
+func jsonDecode(args ...object.Object) object.Object {
+	if err := checkArgCount(args, 1, 1); err != nil {
+		return err
+	}
+	text, err := stringArg("decode", args, 0)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	value, decodeErr := decodeJSONValue(decoder)
+	if decodeErr == nil {
+		if _, decodeErr = decoder.Token(); decodeErr == io.EOF {
+			return value
+		}
+		if decodeErr == nil {
+			decodeErr = errors.New("unexpected data after top-level value")
+		}
+	}
+	return jsonSyntaxError(text, decoder, decodeErr)
+}
+
+func decodeJSONValue(decoder *json.Decoder) (object.Object, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token := token.(type) {
+	case nil:
+		return NULL, nil
+	case bool:
+		return nativeBoolToBooleanObject(token), nil
+	case string:
+		return &object.String{Value: token}, nil
+	case json.Number:
+		return decodeJSONNumber(string(token)), nil
+	case json.Delim:
+		if token == '[' {
+			elements := []object.Object{}
+			for decoder.More() {
+				element, err := decodeJSONValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+				elements = append(elements, element)
+			}
+			_, err := decoder.Token()
+			return &object.Array{Elements: elements}, err
+		}
+		hash := object.NewHash()
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			hash.Set(&object.String{Value: key.(string)}, value)
+		}
+		_, err := decoder.Token()
+		return hash, err
+	}
+	return nil, errors.New("unexpected token")
+}
+
+func decodeJSONNumber(number string) object.Object {
+	if !strings.ContainsAny(number, ".eE") {
+		if value, ok := new(big.Int).SetString(number, 10); ok {
+			return normalizeBigInt(value)
+		}
+	}
+	value, _ := strconv.ParseFloat(number, 64)
+	return &object.Float{Value: value}
+}
+
+//jsonSyntaxError reports a decoding error with the line and column where it happened
+func jsonSyntaxError(text string, decoder *json.Decoder, err error) *object.Error {
+	offset := decoder.InputOffset()
+	message := err.Error()
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	}
+	// offsets point just past the offending character, so at the end of the input
+	// the column is one past the last character
+	past := 0
+	if err == io.ErrUnexpectedEOF || err == io.EOF || message == "unexpected end of JSON input" {
+		offset, message, past = int64(len(text)), "unexpected end of JSON input", 1
+	}
+	if offset > int64(len(text)) {
+		offset = int64(len(text))
+	}
+	prefix := text[:offset]
+	line := strings.Count(prefix, "\n") + 1
+	column := len(prefix) - (strings.LastIndex(prefix, "\n") + 1) + past
+	return newError("invalid JSON at line %d, column %d: %s", line, column, message)
+}
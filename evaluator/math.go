@@ -0,0 +1,232 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"monkey/object"
+)
+
+func init() {
+	registerModule("math", func() *object.Module {
+		members := map[string]object.Object{
+			"pi":  &object.Float{Value: math.Pi},
+			"e":   &object.Float{Value: math.E},
+			"inf": &object.Float{Value: math.Inf(1)},
+			"nan": &object.Float{Value: math.NaN()},
+		}
+		for name, builtin := range mathBuiltins {
+			members[name] = builtin
+		}
+		return newModule("math", members)
+	})
+}
+
+//mathBuiltins are the functions of the math module.
+// floor, ceil, isqrt, gcd and lcm return integers and pow stays exact for integers,
+// everything else works on floats
+var mathBuiltins = map[string]*object.Builtin{
+	"sqrt":  floatFunction("sqrt", math.Sqrt),
+	"sin":   floatFunction("sin", math.Sin),
+	"cos":   floatFunction("cos", math.Cos),
+	"tan":   floatFunction("tan", math.Tan),
+	"asin":  floatFunction("asin", math.Asin),
+	"acos":  floatFunction("acos", math.Acos),
+	"atan":  floatFunction("atan", math.Atan),
+	"exp":   floatFunction("exp", math.Exp),
+	"log2":  floatFunction("log2", math.Log2),
+	"log10": floatFunction("log10", math.Log10),
+	"atan2": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 2); err != nil {
+				return err
+			}
+			y, err := numberArg("atan2", args, 0)
+			if err != nil {
+				return err
+			}
+			x, err := numberArg("atan2", args, 1)
+			if err != nil {
+				return err
+			}
+			return &object.Float{Value: math.Atan2(y, x)}
+		},
+	},
+	"log": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			x, err := numberArg("log", args, 0)
+			if err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				return &object.Float{Value: math.Log(x)}
+			}
+			base, err := numberArg("log", args, 1)
+			if err != nil {
+				return err
+			}
+			return &object.Float{Value: math.Log(x) / math.Log(base)}
+		},
+	},
+	"pow": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 2); err != nil {
+				return err
+			}
+			if isInteger(args[1]) && toBigInt(args[1]).Sign() >= 0 {
+				exponent := toBigInt(args[1])
+				switch base := args[0].(type) {
+				case *object.Integer, *object.BigInt:
+					if powTooLarge(toBigInt(base), exponent) {
+						return newError("exponent %s too large for `pow`", exponent)
+					}
+					return normalizeBigInt(new(big.Int).Exp(toBigInt(base), exponent, nil))
+				case *object.Decimal:
+					// the scale grows with the exponent even when the digits do not
+					if powTooLarge(base.Unscaled, exponent) || exponent.Cmp(big.NewInt(maxPowBits)) > 0 {
+						return newError("exponent %s too large for `pow`", exponent)
+					}
+					unscaled := new(big.Int).Exp(base.Unscaled, exponent, nil)
+					return &object.Decimal{Unscaled: unscaled, Scale: base.Scale * int(exponent.Int64())}
+				}
+			}
+			x, err := numberArg("pow", args, 0)
+			if err != nil {
+				return err
+			}
+			y, err := numberArg("pow", args, 1)
+			if err != nil {
+				return err
+			}
+			return &object.Float{Value: math.Pow(x, y)}
+		},
+	},
+	"floor": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return roundToInteger("floor", args, math.Floor, -1)
+		},
+	},
+	"ceil": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return roundToInteger("ceil", args, math.Ceil, 1)
+		},
+	},
+	"isqrt": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			if !isInteger(args[0]) {
+				return newError("argument to `isqrt` must be INTEGER, got %s", args[0].Type())
+			}
+			n := toBigInt(args[0])
+			if n.Sign() < 0 {
+				return newError("argument to `isqrt` must not be negative")
+			}
+			return normalizeBigInt(new(big.Int).Sqrt(n))
+		},
+	},
+	"gcd": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			a, b, err := twoIntegerArgs("gcd", args)
+			if err != nil {
+				return err
+			}
+			return normalizeBigInt(new(big.Int).GCD(nil, nil, a.Abs(a), b.Abs(b)))
+		},
+	},
+	"lcm": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			a, b, err := twoIntegerArgs("lcm", args)
+			if err != nil {
+				return err
+			}
+			if a.Sign() == 0 || b.Sign() == 0 {
+				return &object.Integer{Value: 0}
+			}
+			gcd := new(big.Int).GCD(nil, nil, new(big.Int).Abs(a), new(big.Int).Abs(b))
+			lcm := new(big.Int).Mul(a, b)
+			lcm.Abs(lcm)
+			return normalizeBigInt(lcm.Quo(lcm, gcd))
+		},
+	},
+}
+
+//numberArg returns the argument at index i as a float64. it must be a number
+func numberArg(builtin string, args []object.Object, i int) (float64, *object.Error) {
+	switch arg := args[i].(type) {
+	case *object.Integer, *object.BigInt, *object.Float:
+		return toFloat(arg), nil
+	case *object.Decimal:
+		value, _ := new(big.Float).SetInt(arg.Unscaled).Float64()
+		return value / math.Pow(10, float64(arg.Scale)), nil
+	default:
+		return 0, newError("argument %d to `%s` must be a number, got %s", i+1, builtin, arg.Type())
+	}
+}
+
+//floatFunction wraps a float function of one argument as a builtin
+func floatFunction(builtin string, fn func(float64) float64) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			x, err := numberArg(builtin, args, 0)
+			if err != nil {
+				return err
+			}
+			return &object.Float{Value: fn(x)}
+		},
+	}
+}
+
+//roundToInteger implements floor and ceil. integers are returned unchanged,
+// decimals are rounded exactly towards direction (-1 for floor, 1 for ceil)
+func roundToInteger(builtin string, args []object.Object, fn func(float64) float64, direction int) object.Object {
+	if err := checkArgCount(args, 1, 1); err != nil {
+		return err
+	}
+	switch arg := args[0].(type) {
+	case *object.Integer, *object.BigInt:
+		return arg
+	case *object.Float:
+		return floatToInteger(fn(arg.Value))
+	case *object.Decimal:
+		divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(arg.Scale)), nil)
+		quotient, remainder := new(big.Int).QuoRem(arg.Unscaled, divisor, new(big.Int))
+		if remainder.Sign() == direction {
+			quotient.Add(quotient, big.NewInt(int64(direction)))
+		}
+		return normalizeBigInt(quotient)
+	default:
+		return newError("argument to `%s` must be a number, got %s", builtin, arg.Type())
+	}
+}
+
+//twoIntegerArgs returns the two integer arguments of gcd and lcm as big.Ints
+func twoIntegerArgs(builtin string, args []object.Object) (*big.Int, *big.Int, *object.Error) {
+	if err := checkArgCount(args, 2, 2); err != nil {
+		return nil, nil, err
+	}
+	for i := range args {
+		if !isInteger(args[i]) {
+			return nil, nil, newError("argument %d to `%s` must be INTEGER, got %s", i+1, builtin, args[i].Type())
+		}
+	}
+	return new(big.Int).Set(toBigInt(args[0])), new(big.Int).Set(toBigInt(args[1])), nil
+}
+
+//maxPowBits bounds the size of exact powers, about five million decimal digits
+const maxPowBits = 1 << 24
+
+//powTooLarge reports whether base ** exponent would take more than maxPowBits bits.
+// powers of 0, 1 and -1 never grow
+func powTooLarge(base, exponent *big.Int) bool {
+	if base.CmpAbs(big.NewInt(1)) <= 0 {
+		return false
+	}
+	return !exponent.IsInt64() || exponent.Int64() > maxPowBits/int64(base.BitLen())
+}
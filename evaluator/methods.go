@@ -0,0 +1,113 @@
+package evaluator
+
+import (
+	"monkey/object"
+)
+
+//builtinMethods holds the methods of builtin types, looked up by evalDotExpression.
+// every method is a builtin that receives the receiver as its first argument
+// eg. "abc".upper() calls builtinMethods[STRING_OBJ]["upper"] with ("abc")
+//
+// the table is filled in init functions because methods such as map call back
+// into the evaluator
+var builtinMethods = map[object.ObjectType]map[string]*object.Builtin{}
+
+//registerMethods adds methods to a builtin type
+func registerMethods(objectType object.ObjectType, methods map[string]*object.Builtin) {
+	if builtinMethods[objectType] == nil {
+		builtinMethods[objectType] = map[string]*object.Builtin{}
+	}
+	for name, method := range methods {
+		builtinMethods[objectType][name] = method
+	}
+}
+
+//registerBuiltinMethods adds methods to a builtin type that are also callable as builtins
+// taking the receiver as their first argument. eg. "a".upper() and upper("a")
+// a builtin shared by several types, such as contains, dispatches on the type of its
+// first argument and falls back to the implementation registered first
+func registerBuiltinMethods(objectType object.ObjectType, methods map[string]*object.Builtin) {
+	registerMethods(objectType, methods)
+	for name, method := range methods {
+		if existing, ok := builtins[name]; ok {
+			builtins[name] = dispatchBuiltin(name, existing)
+		} else {
+			builtins[name] = method
+		}
+	}
+}
+
+//dispatchBuiltin returns a builtin that calls the method of its first argument's type
+func dispatchBuiltin(name string, fallback *object.Builtin) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) > 0 {
+				if method, ok := builtinMethods[args[0].Type()][name]; ok {
+					return method.Fn(args...)
+				}
+			}
+			return fallback.Fn(args...)
+		},
+	}
+}
+
+func init() {
+	registerMethods(object.ENUM_OBJ, map[string]*object.Builtin{
+		"values": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. got=%d, want=0", len(args)-1)
+				}
+				enum := args[0].(*object.Enum)
+				elements := make([]object.Object, len(enum.Members))
+				for i, member := range enum.Members {
+					elements[i] = member
+				}
+				return &object.Array{Elements: elements}
+			},
+		},
+	})
+	registerMethods(object.RECORDINSTANCE_OBJ, map[string]*object.Builtin{
+		"__str__": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				return &object.String{Value: args[0].Inspect()}
+			},
+		},
+		"with": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				return recordWith(args[0].(*object.RecordInstance), args[1:])
+			},
+		},
+	})
+}
+
+//recordWith returns a copy of a record with the fields in the hash argument replaced
+// eg. p.with({"x": 3})
+func recordWith(record *object.RecordInstance, args []object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	changes, ok := args[0].(*object.Hash)
+	if !ok {
+		return newError("argument to `with` must be HASH, got %s", args[0].Type())
+	}
+	values := make([]object.Object, len(record.Values))
+	copy(values, record.Values)
+	for _, pair := range changes.Pairs() {
+		name, ok := pair.Key.(*object.String)
+		if !ok {
+			return newError("record field names must be STRING, got %s", pair.Key.Type())
+		}
+		found := false
+		for i, field := range record.Record.Fields {
+			if field == name.Value {
+				values[i] = pair.Value
+				found = true
+			}
+		}
+		if !found {
+			return newError("record %s has no field %s", record.Record.Name, name.Value)
+		}
+	}
+	return &object.RecordInstance{Record: record.Record, Values: values}
+}
@@ -0,0 +1,44 @@
+package evaluator
+
+import (
+	"monkey/object"
+)
+
+//builtinModules are the modules that can be imported without a .monkey file on disk.
+// eg. import "math"
+// a module is created by its loader on first import. later imports share its members
+// (and whatever state they close over) but get their own environment, so that
+// setattr on one import does not change the module for every other importer
+var builtinModules = map[string]func() *object.Module{}
+
+//loadedModules caches the builtin modules that were already imported
+var loadedModules = map[string]*object.Module{}
+
+//registerModule makes a builtin module importable under name
+func registerModule(name string, load func() *object.Module) {
+	builtinModules[name] = load
+}
+
+//importBuiltinModule returns a new import of the builtin module called name,
+// loading the module on first use
+func importBuiltinModule(name string) (*object.Module, bool) {
+	module, ok := loadedModules[name]
+	if !ok {
+		load, ok := builtinModules[name]
+		if !ok {
+			return nil, false
+		}
+		module = load()
+		loadedModules[name] = module
+	}
+	return &object.Module{Name: module.Name, Env: object.NewEnvironment().ShallowCopy(module.Env)}, true
+}
+
+//newModule creates a module whose environment holds members
+func newModule(name string, members map[string]object.Object) *object.Module {
+	env := object.NewEnvironment()
+	for memberName, member := range members {
+		env.Set(memberName, member)
+	}
+	return &object.Module{Name: name, Env: env}
+}
@@ -0,0 +1,343 @@
+package evaluator
+
+import (
+	"bufio"
+	"io"
+	"monkey/object"
+	"net"
+	"strings"
+	"time"
+)
+
+//maxReadSize is the most bytes one read or receive asks for
+const maxReadSize = 1 << 20
+
+//listenerClass, connectionClass and udpSocketClass are the classes of the objects of the net module
+var (
+	listenerClass   = nativeClass("Listener", nil)
+	connectionClass = nativeClass("Connection", nil)
+	udpSocketClass  = nativeClass("UDPSocket", nil)
+)
+
+func init() {
+	registerModule("net", func() *object.Module {
+		members := map[string]object.Object{
+			"Listener":   listenerClass,
+			"Connection": connectionClass,
+			"UDPSocket":  udpSocketClass,
+		}
+		for name, builtin := range netBuiltins {
+			members[name] = builtin
+		}
+		return newModule("net", members)
+	})
+}
+
+//netBuiltins are the functions of the net module. addresses are host:port strings,
+// with port 0 asking for any free port. eg. net.listen("127.0.0.1:0").addr
+var netBuiltins = map[string]*object.Builtin{
+	// listen(addr) returns a Listener accepting TCP connections on addr
+	"listen": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			addr, err := stringArg("listen", args, 0)
+			if err != nil {
+				return err
+			}
+			listener, listenErr := net.Listen("tcp", addr)
+			if listenErr != nil {
+				return newError("listen: %s", listenErr)
+			}
+			return newListener(listener)
+		},
+	},
+	// dial(addr, timeout) opens a TCP connection, giving up after timeout milliseconds
+	"dial": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			addr, err := stringArg("dial", args, 0)
+			if err != nil {
+				return err
+			}
+			timeout := int64(0)
+			if len(args) == 2 {
+				if timeout, err = integerArg("dial", args, 1); err != nil {
+					return err
+				}
+			}
+			var conn net.Conn
+			var dialErr error
+			whileBlocked(func() { conn, dialErr = net.DialTimeout("tcp", addr, time.Duration(timeout)*time.Millisecond) })
+			if dialErr != nil {
+				return newError("dial: %s", dialErr)
+			}
+			return newConnection(conn)
+		},
+	},
+	// udp(addr) returns a UDPSocket bound to addr, eg. udp("127.0.0.1:0")
+	"udp": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			addr, err := stringArg("udp", args, 0)
+			if err != nil {
+				return err
+			}
+			conn, listenErr := net.ListenPacket("udp", addr)
+			if listenErr != nil {
+				return newError("udp: %s", listenErr)
+			}
+			return newUDPSocket(conn)
+		},
+	},
+}
+
+//newListener returns the Listener object of a TCP listener, with addr, accept() returning
+// the next Connection and close()
+func newListener(listener net.Listener) *object.ClassInstance {
+	return newNativeInstance(listenerClass, map[string]object.Object{
+		"addr": &object.String{Value: listener.Addr().String()},
+		"accept": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				var conn net.Conn
+				var err error
+				whileBlocked(func() { conn, err = listener.Accept() })
+				if err != nil {
+					return newError("accept: %s", err)
+				}
+				return newConnection(conn)
+			},
+		},
+		"close": closeBuiltin(listener),
+	})
+}
+
+//newConnection returns the Connection object of a TCP connection, with localAddr,
+// remoteAddr, read, readLine, write, close and the deadline functions
+func newConnection(conn net.Conn) *object.ClassInstance {
+	reader := bufio.NewReader(conn)
+	members := map[string]object.Object{
+		"localAddr":  &object.String{Value: conn.LocalAddr().String()},
+		"remoteAddr": &object.String{Value: conn.RemoteAddr().String()},
+		// read(n) returns the bytes available, at most n (4096 by default), or null
+		// once the other side has closed the connection
+		"read": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 1); err != nil {
+					return err
+				}
+				buf, err := readBuffer("read", args)
+				if err != nil {
+					return err
+				}
+				var n int
+				var readErr error
+				whileBlocked(func() { n, readErr = reader.Read(buf) })
+				if readErr == io.EOF {
+					return NULL
+				}
+				if readErr != nil {
+					return newError("read: %s", readErr)
+				}
+				return &object.Bytes{Value: buf[:n]}
+			},
+		},
+		// readLine returns the next line without its line ending, or null at the end
+		"readLine": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				var line string
+				var err error
+				whileBlocked(func() { line, err = reader.ReadString('\n') })
+				if err == io.EOF && line == "" {
+					return NULL
+				}
+				if err != nil && err != io.EOF {
+					return newError("readLine: %s", err)
+				}
+				return &object.String{Value: strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")}
+			},
+		},
+		// write(data) sends bytes or a string and returns the number of bytes written
+		"write": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 1, 1); err != nil {
+					return err
+				}
+				data, err := bytesArg("write", args, 0)
+				if err != nil {
+					return err
+				}
+				var n int
+				var writeErr error
+				whileBlocked(func() { n, writeErr = conn.Write(data) })
+				if writeErr != nil {
+					return newError("write: %s", writeErr)
+				}
+				return &object.Integer{Value: int64(n)}
+			},
+		},
+		"close": closeBuiltin(conn),
+	}
+	for name, builtin := range deadlineBuiltins(conn) {
+		members[name] = builtin
+	}
+	return newNativeInstance(connectionClass, members)
+}
+
+//newUDPSocket returns the UDPSocket object of a UDP socket, with addr, send(data, addr),
+// receive(n) returning the tuple (data, sender), close and the deadline functions
+func newUDPSocket(conn net.PacketConn) *object.ClassInstance {
+	members := map[string]object.Object{
+		"addr": &object.String{Value: conn.LocalAddr().String()},
+		"send": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 2, 2); err != nil {
+					return err
+				}
+				data, err := bytesArg("send", args, 0)
+				if err != nil {
+					return err
+				}
+				target, err := stringArg("send", args, 1)
+				if err != nil {
+					return err
+				}
+				addr, resolveErr := net.ResolveUDPAddr("udp", target)
+				if resolveErr != nil {
+					return newError("send: %s", resolveErr)
+				}
+				var n int
+				var sendErr error
+				whileBlocked(func() { n, sendErr = conn.WriteTo(data, addr) })
+				if sendErr != nil {
+					return newError("send: %s", sendErr)
+				}
+				return &object.Integer{Value: int64(n)}
+			},
+		},
+		// receive(n) waits for a datagram, keeping at most n (65535 by default) of its bytes
+		"receive": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 1); err != nil {
+					return err
+				}
+				buf, err := readBuffer("receive", args)
+				if err != nil {
+					return err
+				}
+				if len(args) == 0 {
+					buf = make([]byte, 65535)
+				}
+				var n int
+				var addr net.Addr
+				var receiveErr error
+				whileBlocked(func() { n, addr, receiveErr = conn.ReadFrom(buf) })
+				if receiveErr != nil {
+					return newError("receive: %s", receiveErr)
+				}
+				return &object.Tuple{Elements: []object.Object{
+					&object.Bytes{Value: buf[:n]},
+					&object.String{Value: addr.String()},
+				}}
+			},
+		},
+		"close": closeBuiltin(conn),
+	}
+	for name, builtin := range deadlineBuiltins(conn) {
+		members[name] = builtin
+	}
+	return newNativeInstance(udpSocketClass, members)
+}
+
+//readBuffer returns a buffer of the size given as the optional argument, 4096 by default
+// and at most maxReadSize
+func readBuffer(builtin string, args []object.Object) ([]byte, *object.Error) {
+	if len(args) == 0 {
+		return make([]byte, 4096), nil
+	}
+	n, err := integerArg(builtin, args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, newError("argument to `%s` must be positive, got %d", builtin, n)
+	}
+	if n > maxReadSize {
+		return nil, newError("argument to `%s` must be at most %d", builtin, maxReadSize)
+	}
+	return make([]byte, n), nil
+}
+
+//closeBuiltin returns the close function of a listener or connection
+func closeBuiltin(c io.Closer) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 0, 0); err != nil {
+				return err
+			}
+			if err := c.Close(); err != nil {
+				return newError("close: %s", err)
+			}
+			return NULL
+		},
+	}
+}
+
+//deadlineConn is what connections and UDP sockets have in common for deadlines
+type deadlineConn interface {
+	SetDeadline(t time.Time) error
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+}
+
+//deadlineBuiltins returns setDeadline, setReadDeadline and setWriteDeadline. each takes
+// a timeout in milliseconds from now, a Duration or a DateTime; null or 0 removes the
+// deadline. reads and writes after the deadline fail with an i/o timeout error
+func deadlineBuiltins(conn deadlineConn) map[string]*object.Builtin {
+	setters := map[string]func(time.Time) error{
+		"setDeadline":      conn.SetDeadline,
+		"setReadDeadline":  conn.SetReadDeadline,
+		"setWriteDeadline": conn.SetWriteDeadline,
+	}
+	builtins := map[string]*object.Builtin{}
+	for name, set := range setters {
+		name, set := name, set
+		builtins[name] = &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 1, 1); err != nil {
+					return err
+				}
+				var deadline time.Time
+				switch arg := args[0].(type) {
+				case *object.Null:
+				case *object.Integer:
+					if arg.Value != 0 {
+						deadline = time.Now().Add(time.Duration(arg.Value) * time.Millisecond)
+					}
+				case *object.Duration:
+					deadline = time.Now().Add(arg.Value)
+				case *object.DateTime:
+					deadline = arg.Value
+				default:
+					return newError("argument to `%s` must be INTEGER, DURATION or DATETIME, got %s", name, arg.Type())
+				}
+				if err := set(deadline); err != nil {
+					return newError("%s: %s", name, err)
+				}
+				return NULL
+			},
+		}
+	}
+	return builtins
+}
@@ -0,0 +1,484 @@
+package evaluator
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"monkey/object"
+	"strconv"
+	"strings"
+)
+
+//maxFloatDigits is the most decimal places the shortest form of a float64 needs, as in
+// 5e-324 or 2.2250738585072014e-308. rounding to more places leaves a float unchanged
+const maxFloatDigits = 324
+
+//decimalDivisionDigits is the number of extra decimal places kept when a decimal
+// division does not terminate. eg. decimal(1) / 3
+const decimalDivisionDigits = 28
+
+//isInteger reports whether obj is an Integer or a BigInt
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIGINT_OBJ
+}
+
+//toBigInt converts an Integer or a BigInt into a big.Int
+func toBigInt(obj object.Object) *big.Int {
+	if integer, ok := obj.(*object.Integer); ok {
+		return big.NewInt(integer.Value)
+	}
+	return obj.(*object.BigInt).Value
+}
+
+//toFloat converts an Integer, BigInt or Float into a float64
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInt:
+		f, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return f
+	default:
+		return obj.(*object.Float).Value
+	}
+}
+
+//toDecimal converts an Integer, BigInt or Decimal into a Decimal
+func toDecimal(obj object.Object) *object.Decimal {
+	if decimal, ok := obj.(*object.Decimal); ok {
+		return decimal
+	}
+	return &object.Decimal{Unscaled: toBigInt(obj), Scale: 0}
+}
+
+//normalizeBigInt returns an Integer when value fits in an int64 and a BigInt otherwise
+func normalizeBigInt(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+	return &object.BigInt{Value: value}
+}
+
+//int64Arithmetic applies +, - or * and reports false when the result overflows
+func int64Arithmetic(operator string, a, b int64) (int64, bool) {
+	switch operator {
+	case "+":
+		result := a + b
+		return result, (result > a) == (b > 0)
+	case "-":
+		result := a - b
+		return result, (result < a) == (b > 0)
+	default:
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		result := a * b
+		overflow := result/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64)
+		return result, !overflow
+	}
+}
+
+//evalBigIntInfixExpression evaluates infix expressions on integers that may not fit in an int64.
+// results that fit are demoted back to an Integer
+func evalBigIntInfixExpression(operator string, left, right *big.Int) object.Object {
+	switch operator {
+	case "+":
+		return normalizeBigInt(new(big.Int).Add(left, right))
+	case "-":
+		return normalizeBigInt(new(big.Int).Sub(left, right))
+	case "*":
+		return normalizeBigInt(new(big.Int).Mul(left, right))
+	case "/":
+		// exact divisions stay integers, everything else becomes a float
+		if right.Sign() != 0 {
+			quotient, remainder := new(big.Int).QuoRem(left, right, new(big.Int))
+			if remainder.Sign() == 0 {
+				return normalizeBigInt(quotient)
+			}
+		}
+		leftVal, _ := new(big.Float).SetInt(left).Float64()
+		rightVal, _ := new(big.Float).SetInt(right).Float64()
+		return &object.Float{Value: leftVal / rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(left.Cmp(right) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(left.Cmp(right) > 0)
+	case "==":
+		return nativeBoolToBooleanObject(left.Cmp(right) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(left.Cmp(right) != 0)
+	default:
+		return newError("unknown operator: BIGINT %s BIGINT", operator)
+	}
+}
+
+//evalDecimalInfixExpression evaluates infix expressions on decimals.
+// +, - and * are exact, / rounds half to even after decimalDivisionDigits places
+func evalDecimalInfixExpression(operator string, left, right *object.Decimal) object.Object {
+	scale := left.Scale
+	if right.Scale > scale {
+		scale = right.Scale
+	}
+	switch operator {
+	case "+":
+		return &object.Decimal{Unscaled: new(big.Int).Add(left.Rescale(scale), right.Rescale(scale)), Scale: scale}
+	case "-":
+		return &object.Decimal{Unscaled: new(big.Int).Sub(left.Rescale(scale), right.Rescale(scale)), Scale: scale}
+	case "*":
+		return &object.Decimal{Unscaled: new(big.Int).Mul(left.Unscaled, right.Unscaled), Scale: left.Scale + right.Scale}
+	case "/":
+		return divideDecimals(left, right)
+	case "<":
+		return nativeBoolToBooleanObject(left.Cmp(right) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(left.Cmp(right) > 0)
+	case "==":
+		return nativeBoolToBooleanObject(left.Cmp(right) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(left.Cmp(right) != 0)
+	default:
+		return newError("unknown operator: DECIMAL %s DECIMAL", operator)
+	}
+}
+
+//evalDecimalFloatInfixExpression compares a decimal with a float. the float is read as the
+// decimal of its shortest representation, as decimal(0.1) does, so decimal("0.1") == 0.1.
+// arithmetic mixing the two stays a type mismatch: the float has already lost the
+// precision decimals are used for, so scripts must convert one side explicitly
+func evalDecimalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	switch operator {
+	case "<", ">", "==", "!=":
+	default:
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+	f, ok := left.(*object.Float)
+	if !ok {
+		f = right.(*object.Float)
+	}
+	if math.IsNaN(f.Value) || math.IsInf(f.Value, 0) {
+		// no decimal equals them, and infinities are beyond every decimal
+		return evalFloatInfixExpression(operator, decimalOrFloat(left), decimalOrFloat(right))
+	}
+	converted := parseDecimal(strconv.FormatFloat(f.Value, 'f', -1, 64)).(*object.Decimal)
+	if left == f {
+		return evalDecimalInfixExpression(operator, converted, right.(*object.Decimal))
+	}
+	return evalDecimalInfixExpression(operator, left.(*object.Decimal), converted)
+}
+
+//decimalOrFloat returns obj as a Float, converting decimals the way numberArg does
+func decimalOrFloat(obj object.Object) *object.Float {
+	decimal, ok := obj.(*object.Decimal)
+	if !ok {
+		return obj.(*object.Float)
+	}
+	value, _ := new(big.Float).SetInt(decimal.Unscaled).Float64()
+	return &object.Float{Value: value / math.Pow(10, float64(decimal.Scale))}
+}
+
+//divideDecimals divides two decimals. the result keeps at least the difference of
+// the operand scales as decimal places. eg. 10.00 / 4 = 2.50
+func divideDecimals(left, right *object.Decimal) object.Object {
+	if right.Unscaled.Sign() == 0 {
+		return newError("division by zero")
+	}
+	minScale := left.Scale - right.Scale
+	if minScale < 0 {
+		minScale = 0
+	}
+	scale := minScale + decimalDivisionDigits
+	// left / right = (left.Unscaled * 10^(scale - left.Scale + right.Scale)) / right.Unscaled * 10^-scale
+	exponent := big.NewInt(int64(scale - left.Scale + right.Scale))
+	numerator := new(big.Int).Mul(left.Unscaled, new(big.Int).Exp(big.NewInt(10), exponent, nil))
+	quotient := roundHalfEven(numerator, right.Unscaled)
+	return (&object.Decimal{Unscaled: quotient, Scale: scale}).Trim(minScale)
+}
+
+//roundHalfEven divides numerator by denominator, rounding ties to the even quotient
+func roundHalfEven(numerator, denominator *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	if cmp := twice.Cmp(new(big.Int).Abs(denominator)); cmp > 0 || (cmp == 0 && quotient.Bit(0) == 1) {
+		if numerator.Sign()*denominator.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+//isNaN reports whether obj is a Float holding NaN
+func isNaN(obj object.Object) bool {
+	f, ok := obj.(*object.Float)
+	return ok && math.IsNaN(f.Value)
+}
+
+//lessThan orders two values for sort, min and max. it follows the < operator
+// except that NaN is placed after every number instead of being unordered
+func lessThan(a, b object.Object) object.Object {
+	if isNaN(a) || isNaN(b) {
+		return nativeBoolToBooleanObject(!isNaN(a))
+	}
+	return evalInfixExpression("<", a, b)
+}
+
+//numberBuiltins convert between and compute with the numeric types
+var numberBuiltins = map[string]*object.Builtin{
+	"int": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			switch arg := args[0].(type) {
+			case *object.Integer, *object.BigInt:
+				return arg
+			case *object.Float:
+				return floatToInteger(arg.Value)
+			case *object.Decimal:
+				// truncates towards zero like int() of a float
+				divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(arg.Scale)), nil)
+				return normalizeBigInt(new(big.Int).Quo(arg.Unscaled, divisor))
+			case *object.Boolean:
+				if arg.Value {
+					return &object.Integer{Value: 1}
+				}
+				return &object.Integer{Value: 0}
+			case *object.String:
+				return parseInteger(arg.Value, 10)
+			default:
+				return newError("argument to `int` not supported, got %s", arg.Type())
+			}
+		},
+	},
+	"float": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			switch arg := args[0].(type) {
+			case *object.Float:
+				return arg
+			case *object.Integer, *object.BigInt:
+				return &object.Float{Value: toFloat(arg)}
+			case *object.Decimal:
+				value, _ := strconv.ParseFloat(arg.Inspect(), 64)
+				return &object.Float{Value: value}
+			case *object.String:
+				value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+				if err != nil && !errors.Is(err, strconv.ErrRange) {
+					return newError("could not parse %q as float", arg.Value)
+				}
+				return &object.Float{Value: value}
+			default:
+				return newError("argument to `float` not supported, got %s", arg.Type())
+			}
+		},
+	},
+	"parseInt": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			s, err := stringArg("parseInt", args, 0)
+			if err != nil {
+				return err
+			}
+			base := int64(10)
+			if len(args) == 2 {
+				if base, err = integerArg("parseInt", args, 1); err != nil {
+					return err
+				}
+				if base != 0 && (base < 2 || base > 36) {
+					return newError("base to `parseInt` must be 0 or between 2 and 36, got %d", base)
+				}
+			}
+			return parseInteger(s, int(base))
+		},
+	},
+	"round": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				switch arg := args[0].(type) {
+				case *object.Integer, *object.BigInt:
+					return arg
+				case *object.Float:
+					return floatToInteger(math.RoundToEven(arg.Value))
+				}
+			}
+			digits := int64(0)
+			if len(args) == 2 {
+				var err *object.Error
+				if digits, err = integerArg("round", args, 1); err != nil {
+					return err
+				}
+				if digits < 0 {
+					return newError("digits to `round` must not be negative, got %d", digits)
+				}
+			}
+			switch arg := args[0].(type) {
+			case *object.Integer, *object.BigInt:
+				return arg
+			case *object.Float:
+				if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) || digits > maxFloatDigits {
+					return arg
+				}
+				// formatting rounds the exact binary value, ties to even
+				value, _ := strconv.ParseFloat(strconv.FormatFloat(arg.Value, 'f', int(digits), 64), 64)
+				return &object.Float{Value: value}
+			case *object.Decimal:
+				if arg.Scale <= int(digits) {
+					return arg
+				}
+				divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(arg.Scale)-digits), nil)
+				return &object.Decimal{Unscaled: roundHalfEven(arg.Unscaled, divisor), Scale: int(digits)}
+			default:
+				return newError("argument to `round` must be a number, got %s", arg.Type())
+			}
+		},
+	},
+	"abs": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				if arg.Value < 0 {
+					return evalMinusPrefixOperatorExpression(arg)
+				}
+				return arg
+			case *object.BigInt:
+				return &object.BigInt{Value: new(big.Int).Abs(arg.Value)}
+			case *object.Float:
+				return &object.Float{Value: math.Abs(arg.Value)}
+			case *object.Decimal:
+				return &object.Decimal{Unscaled: new(big.Int).Abs(arg.Unscaled), Scale: arg.Scale}
+			default:
+				return newError("argument to `abs` must be a number, got %s", arg.Type())
+			}
+		},
+	},
+	"min": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return extremum("min", args, func(candidate, best object.Object) object.Object {
+				return lessThan(candidate, best)
+			})
+		},
+	},
+	"max": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return extremum("max", args, func(candidate, best object.Object) object.Object {
+				return lessThan(best, candidate)
+			})
+		},
+	},
+	"isNaN": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(isNaN(args[0]))
+		},
+	},
+	"isInf": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			f, ok := args[0].(*object.Float)
+			return nativeBoolToBooleanObject(ok && math.IsInf(f.Value, 0))
+		},
+	},
+}
+
+//floatToInteger truncates a float towards zero into an Integer or a BigInt
+func floatToInteger(value float64) object.Object {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return newError("cannot convert %s to integer", (&object.Float{Value: value}).Inspect())
+	}
+	if value >= math.MinInt64 && value < math.MaxInt64 {
+		return &object.Integer{Value: int64(value)}
+	}
+	integer, _ := big.NewFloat(value).Int(nil)
+	return normalizeBigInt(integer)
+}
+
+//parseInteger parses a string in the given base into an Integer or a BigInt
+func parseInteger(s string, base int) object.Object {
+	value, ok := new(big.Int).SetString(strings.TrimSpace(s), base)
+	if !ok {
+		return newError("could not parse %q as integer in base %d", s, base)
+	}
+	return normalizeBigInt(value)
+}
+
+//extremum implements min and max over either its arguments or a single array.
+// better reports whether candidate should replace the current best value
+// NaN is returned as soon as it is seen
+func extremum(builtin string, args []object.Object, better func(candidate, best object.Object) object.Object) object.Object {
+	values := args
+	if len(args) == 1 {
+		arr, ok := args[0].(*object.Array)
+		if !ok {
+			return newError("argument to `%s` must be ARRAY when called with one argument, got %s", builtin, args[0].Type())
+		}
+		values = arr.Elements
+	}
+	if len(values) == 0 {
+		return newError("`%s` of an empty sequence", builtin)
+	}
+	best := values[0]
+	for _, value := range values {
+		if isNaN(value) {
+			return value
+		}
+		replace := better(value, best)
+		if isError(replace) {
+			return replace
+		}
+		if replace == TRUE {
+			best = value
+		}
+	}
+	return best
+}
+
+func init() {
+	for name, builtin := range numberBuiltins {
+		builtins[name] = builtin
+	}
+	builtins["decimal"] = &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			switch arg := args[0].(type) {
+			case *object.Decimal:
+				return arg
+			case *object.Integer, *object.BigInt:
+				return toDecimal(arg)
+			case *object.Float:
+				// the shortest representation that round trips. eg. decimal(0.1) is 0.1
+				return parseDecimal(strconv.FormatFloat(arg.Value, 'f', -1, 64))
+			case *object.String:
+				return parseDecimal(arg.Value)
+			default:
+				return newError("argument to `decimal` not supported, got %s", arg.Type())
+			}
+		},
+	}
+}
+
+//parseDecimal parses a string into a Decimal object or an Error
+func parseDecimal(s string) object.Object {
+	decimal, err := object.ParseDecimal(s)
+	if err != nil {
+		return newError("%s", err)
+	}
+	return decimal
+}
@@ -0,0 +1,156 @@
+package evaluator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"monkey/object"
+	"os"
+	"strings"
+)
+
+//stdin and stdout are what input and readLine talk to, replaceable in tests
+var (
+	stdin            = bufio.NewReader(os.Stdin)
+	stdout io.Writer = os.Stdout
+)
+
+//Stdin returns the reader input and readLine consume. anything else reading standard
+// input must read through it too, since a second buffered reader would swallow lines
+func Stdin() *bufio.Reader {
+	return stdin
+}
+
+//exit ends the process for os.exit, replaceable in tests
+var exit = os.Exit
+
+//scriptArgs are the command line arguments of the running script, starting with its path
+var scriptArgs = []string{}
+
+//SetArgs sets the arguments scripts see as os.args
+func SetArgs(args []string) {
+	scriptArgs = args
+	// os.args is built when the module is loaded
+	delete(loadedModules, "os")
+}
+
+func init() {
+	builtins["input"] = &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 0, 1); err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				prompt, err := stringArg("input", args, 0)
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(stdout, prompt)
+			}
+			return readStdinLine("input")
+		},
+	}
+	builtins["readLine"] = &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 0, 0); err != nil {
+				return err
+			}
+			return readStdinLine("readLine")
+		},
+	}
+
+	registerModule("os", func() *object.Module {
+		members := map[string]object.Object{
+			"args":    stringsToArray(scriptArgs),
+			"Process": processClass,
+		}
+		for name, builtin := range osBuiltins {
+			members[name] = builtin
+		}
+		return newModule("os", members)
+	})
+}
+
+//readStdinLine reads the next line of standard input without its line ending.
+// it returns null once the input is exhausted
+func readStdinLine(builtin string) object.Object {
+	line, err := stdin.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return NULL
+	}
+	if err != nil && err != io.EOF {
+		return newError("%s: %s", builtin, err)
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return &object.String{Value: strings.TrimSuffix(line, "\r")}
+}
+
+//osBuiltins are the functions of the os module
+var osBuiltins = map[string]*object.Builtin{
+	// env returns the value of an environment variable, or the default (null) when it is not set
+	"env": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			name, err := stringArg("env", args, 0)
+			if err != nil {
+				return err
+			}
+			if value, ok := os.LookupEnv(name); ok {
+				return &object.String{Value: value}
+			}
+			if len(args) == 2 {
+				return args[1]
+			}
+			return NULL
+		},
+	},
+	"setEnv": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 2); err != nil {
+				return err
+			}
+			name, err := stringArg("setEnv", args, 0)
+			if err != nil {
+				return err
+			}
+			value, err := stringArg("setEnv", args, 1)
+			if err != nil {
+				return err
+			}
+			if setErr := os.Setenv(name, value); setErr != nil {
+				return newError("setEnv: %s", setErr)
+			}
+			return NULL
+		},
+	},
+	"exit": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 0, 1); err != nil {
+				return err
+			}
+			code := int64(0)
+			if len(args) == 1 {
+				var err *object.Error
+				if code, err = integerArg("exit", args, 0); err != nil {
+					return err
+				}
+			}
+			exit(int(code))
+			return NULL
+		},
+	},
+	"cwd": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 0, 0); err != nil {
+				return err
+			}
+			dir, err := os.Getwd()
+			if err != nil {
+				return newError("cwd: %s", err)
+			}
+			return &object.String{Value: dir}
+		},
+	},
+}
@@ -0,0 +1,160 @@
+package evaluator
+
+import (
+	"math/big"
+	"math/rand"
+	"monkey/object"
+	"sync"
+	"time"
+)
+
+//randomSource a seedable stream of random numbers shared by the functions built on it.
+// the lock lets concurrent requests share the default stream of the random module
+type randomSource struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+//newRandomSource creates a stream seeded with seed
+func newRandomSource(seed int64) *randomSource {
+	return &randomSource{rng: rand.New(rand.NewSource(seed))}
+}
+
+//generatorClass is the class of random.Generator objects. eg. random.Generator(seed)
+var generatorClass *object.Class
+
+func init() {
+	generatorClass = nativeClass("Generator", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 0, 1); err != nil {
+				return err
+			}
+			seed := time.Now().UnixNano()
+			if len(args) == 1 {
+				var err *object.Error
+				if seed, err = integerArg("Generator", args, 0); err != nil {
+					return err
+				}
+			}
+			return newNativeInstance(generatorClass, randomFunctions(newRandomSource(seed)))
+		},
+	})
+	registerModule("random", func() *object.Module {
+		source := newRandomSource(time.Now().UnixNano())
+		members := randomFunctions(source)
+		members["seed"] = &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 1, 1); err != nil {
+					return err
+				}
+				seed, err := integerArg("seed", args, 0)
+				if err != nil {
+					return err
+				}
+				source.mu.Lock()
+				source.rng = rand.New(rand.NewSource(seed))
+				source.mu.Unlock()
+				return NULL
+			},
+		}
+		members["Generator"] = generatorClass
+		return newModule("random", members)
+	})
+}
+
+//randomFunctions returns the functions drawing from source. they make up both the
+// random module and every random.Generator object
+func randomFunctions(source *randomSource) map[string]object.Object {
+	return map[string]object.Object{
+		"int": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 2, 2); err != nil {
+					return err
+				}
+				if !isInteger(args[0]) || !isInteger(args[1]) {
+					return newError("arguments to `int` must be INTEGER, got %s and %s", args[0].Type(), args[1].Type())
+				}
+				lo, hi := toBigInt(args[0]), toBigInt(args[1])
+				if lo.Cmp(hi) > 0 {
+					return newError("empty range for `int`: %s > %s", lo, hi)
+				}
+				// both bounds are included
+				n := new(big.Int).Sub(hi, lo)
+				n.Add(n, big.NewInt(1))
+				source.mu.Lock()
+				value := new(big.Int).Rand(source.rng, n)
+				source.mu.Unlock()
+				return normalizeBigInt(value.Add(value, lo))
+			},
+		},
+		"float": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 0, 0); err != nil {
+					return err
+				}
+				source.mu.Lock()
+				defer source.mu.Unlock()
+				return &object.Float{Value: source.rng.Float64()}
+			},
+		},
+		"choice": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 1, 1); err != nil {
+					return err
+				}
+				elements, err := iterableElements("choice", args[0])
+				if err != nil {
+					return err
+				}
+				if len(elements) == 0 {
+					return newError("cannot choose from an empty sequence")
+				}
+				source.mu.Lock()
+				defer source.mu.Unlock()
+				return elements[source.rng.Intn(len(elements))]
+			},
+		},
+		"shuffle": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 1, 1); err != nil {
+					return err
+				}
+				arr, err := arrayArg("shuffle", args, 0)
+				if err != nil {
+					return err
+				}
+				source.mu.Lock()
+				defer source.mu.Unlock()
+				source.rng.Shuffle(len(arr.Elements), func(i, j int) {
+					arr.Elements[i], arr.Elements[j] = arr.Elements[j], arr.Elements[i]
+				})
+				return arr
+			},
+		},
+		"sample": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount(args, 2, 2); err != nil {
+					return err
+				}
+				elements, err := iterableElements("sample", args[0])
+				if err != nil {
+					return err
+				}
+				k, err := integerArg("sample", args, 1)
+				if err != nil {
+					return err
+				}
+				if k < 0 || k > int64(len(elements)) {
+					return newError("sample size %d out of range for a sequence of %d elements", k, len(elements))
+				}
+				source.mu.Lock()
+				defer source.mu.Unlock()
+				sample := make([]object.Object, k)
+				for i, j := range source.rng.Perm(len(elements))[:k] {
+					sample[i] = elements[j]
+				}
+				return &object.Array{Elements: sample}
+			},
+		},
+	}
+}
@@ -0,0 +1,230 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+//regexMatch is the record describing a match. start and end are character indices,
+// groups holds the capture groups (null when a group did not take part) and named
+// maps the names of named groups to their text
+var regexMatch = &object.Record{Name: "Match", Fields: []string{"text", "start", "end", "groups", "named"}}
+
+func init() {
+	registerModule("re", func() *object.Module {
+		members := map[string]object.Object{
+			"compile": &object.Builtin{
+				Fn: func(args ...object.Object) object.Object {
+					if err := checkArgCount(args, 1, 2); err != nil {
+						return err
+					}
+					pattern, err := stringArg("compile", args, 0)
+					if err != nil {
+						return err
+					}
+					flags := ""
+					if len(args) == 2 {
+						if flags, err = stringArg("compile", args, 1); err != nil {
+							return err
+						}
+					}
+					return compileRegex(pattern, flags)
+				},
+			},
+			"escape": &object.Builtin{
+				Fn: func(args ...object.Object) object.Object {
+					if err := checkArgCount(args, 1, 1); err != nil {
+						return err
+					}
+					text, err := stringArg("escape", args, 0)
+					if err != nil {
+						return err
+					}
+					return &object.String{Value: regexp.QuoteMeta(text)}
+				},
+			},
+		}
+		for name, builtin := range regexBuiltins {
+			members[name] = builtin
+		}
+		return newModule("re", members)
+	})
+	// the same functions are methods of compiled patterns. eg. r/\d+/.findAll("1 2")
+	registerMethods(object.REGEX_OBJ, regexBuiltins)
+}
+
+//regexBuiltins take a compiled pattern or a pattern string as their first argument
+var regexBuiltins = map[string]*object.Builtin{
+	"match": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			re, text, err := regexAndTextArgs("match", args, 2, 2)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(re.MatchString(text))
+		},
+	},
+	"find": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			re, text, err := regexAndTextArgs("find", args, 2, 2)
+			if err != nil {
+				return err
+			}
+			match := re.FindStringSubmatchIndex(text)
+			if match == nil {
+				return NULL
+			}
+			return newRegexMatch(re, text, match)
+		},
+	},
+	// findAll(re, text, n) returns every match, or at most n of them
+	"findAll": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			re, text, err := regexAndTextArgs("findAll", args, 2, 3)
+			if err != nil {
+				return err
+			}
+			n, err := optionalCount("findAll", args, 2)
+			if err != nil {
+				return err
+			}
+			matches := []object.Object{}
+			for _, match := range re.FindAllStringSubmatchIndex(text, n) {
+				matches = append(matches, newRegexMatch(re, text, match))
+			}
+			return &object.Array{Elements: matches}
+		},
+	},
+	// replace(re, text, replacement, n) replaces every match, or the first n of them.
+	// replacement is a string where $1 or ${name} stand for groups, or a function
+	// called with the Match returning the replacement string
+	"replace": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			re, text, err := regexAndTextArgs("replace", args, 3, 4)
+			if err != nil {
+				return err
+			}
+			n, err := optionalCount("replace", args, 3)
+			if err != nil {
+				return err
+			}
+			var out strings.Builder
+			last := 0
+			for _, match := range re.FindAllStringSubmatchIndex(text, n) {
+				out.WriteString(text[last:match[0]])
+				switch replacement := args[2].(type) {
+				case *object.String:
+					out.Write(re.ExpandString(nil, replacement.Value, text, match))
+				default:
+					result := applyFunction(replacement, []object.Object{newRegexMatch(re, text, match)})
+					if isError(result) {
+						return result
+					}
+					s, ok := result.(*object.String)
+					if !ok {
+						return newError("replacement function must return STRING, got %s", result.Type())
+					}
+					out.WriteString(s.Value)
+				}
+				last = match[1]
+			}
+			out.WriteString(text[last:])
+			return &object.String{Value: out.String()}
+		},
+	},
+	// split(re, text, n) splits around every match, into at most n parts when n is given
+	"split": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			re, text, err := regexAndTextArgs("split", args, 2, 3)
+			if err != nil {
+				return err
+			}
+			n, err := optionalCount("split", args, 2)
+			if err != nil {
+				return err
+			}
+			return stringsToArray(re.Split(text, n))
+		},
+	},
+}
+
+//compileRegex compiles pattern with flags, any of i (ignore case), m (multi line),
+// s (dot matches newlines) and U (ungreedy)
+func compileRegex(pattern, flags string) object.Object {
+	for _, flag := range flags {
+		if !strings.ContainsRune("imsU", flag) {
+			return newError("unknown regex flag %c", flag)
+		}
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return newError("invalid regex: %s", err)
+	}
+	return &object.Regex{Value: re}
+}
+
+//regexAndTextArgs returns the pattern and the text the regex functions work on
+func regexAndTextArgs(builtin string, args []object.Object, min, max int) (*regexp.Regexp, string, *object.Error) {
+	if err := checkArgCount(args, min, max); err != nil {
+		return nil, "", err
+	}
+	var re *regexp.Regexp
+	switch pattern := args[0].(type) {
+	case *object.Regex:
+		re = pattern.Value
+	case *object.String:
+		compiled := compileRegex(pattern.Value, "")
+		if err, ok := compiled.(*object.Error); ok {
+			return nil, "", err
+		}
+		re = compiled.(*object.Regex).Value
+	default:
+		return nil, "", newError("argument 1 to `%s` must be REGEX or STRING, got %s", builtin, pattern.Type())
+	}
+	text, err := stringArg(builtin, args, 1)
+	if err != nil {
+		return nil, "", err
+	}
+	return re, text, nil
+}
+
+//optionalCount returns the limit at index i, -1 (no limit) when it is missing
+func optionalCount(builtin string, args []object.Object, i int) (int, *object.Error) {
+	if len(args) <= i {
+		return -1, nil
+	}
+	n, err := integerArg(builtin, args, i)
+	return int(n), err
+}
+
+//newRegexMatch builds the Match record of a match given as byte offsets into text
+func newRegexMatch(re *regexp.Regexp, text string, match []int) *object.RecordInstance {
+	group := func(i int) object.Object {
+		if match[2*i] < 0 {
+			return NULL
+		}
+		return &object.String{Value: text[match[2*i]:match[2*i+1]]}
+	}
+	groups := make([]object.Object, re.NumSubexp())
+	for i := range groups {
+		groups[i] = group(i + 1)
+	}
+	named := object.NewHash()
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			named.Set(&object.String{Value: name}, group(i))
+		}
+	}
+	return &object.RecordInstance{Record: regexMatch, Values: []object.Object{
+		group(0),
+		&object.Integer{Value: int64(utf8.RuneCountInString(text[:match[0]]))},
+		&object.Integer{Value: int64(utf8.RuneCountInString(text[:match[1]]))},
+		&object.Array{Elements: groups},
+		named,
+	}}
+}
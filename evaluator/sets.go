@@ -0,0 +1,136 @@
+package evaluator
+
+import (
+	"monkey/object"
+)
+
+//setBuiltins are available both as builtins taking the set as their first
+// argument and as methods on sets. eg. add(s, 1) or s.add(1)
+// add and remove change the set in place
+var setBuiltins = map[string]*object.Builtin{
+	"add": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			set, element, err := setAndElementArgs("add", args)
+			if err != nil {
+				return err
+			}
+			set.Add(element)
+			return set
+		},
+	},
+	"remove": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			set, element, err := setAndElementArgs("remove", args)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(set.Remove(element))
+		},
+	},
+	"has": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			set, element, err := setAndElementArgs("has", args)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(set.Has(element))
+		},
+	},
+	"values": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			set, ok := args[0].(*object.Set)
+			if !ok {
+				return newError("argument 1 to `values` must be SET, got %s", args[0].Type())
+			}
+			return &object.Array{Elements: set.Elements()}
+		},
+	},
+}
+
+func init() {
+	registerBuiltinMethods(object.SET_OBJ, setBuiltins)
+	builtins["set"] = &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 0, 1); err != nil {
+				return err
+			}
+			set := object.NewSet()
+			if len(args) == 0 {
+				return set
+			}
+			elements, err := iterableElements("set", args[0])
+			if err != nil {
+				return err
+			}
+			for _, element := range elements {
+				hashable, ok := object.AsHashKey(element)
+				if !ok {
+					return newError("unusable as set element: %s", element.Type())
+				}
+				set.Add(hashable)
+			}
+			return set
+		},
+	}
+	builtins["tuple"] = &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 0, 1); err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				return &object.Tuple{Elements: []object.Object{}}
+			}
+			elements, err := iterableElements("tuple", args[0])
+			if err != nil {
+				return err
+			}
+			return &object.Tuple{Elements: append([]object.Object{}, elements...)}
+		},
+	}
+}
+
+//iterableElements returns the elements of an array, tuple or set, the keys of a hash
+// or the characters of a string
+func iterableElements(builtin string, obj object.Object) ([]object.Object, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Array:
+		return obj.Elements, nil
+	case *object.Tuple:
+		return obj.Elements, nil
+	case *object.Set:
+		return obj.Elements(), nil
+	case *object.Hash:
+		elements := []object.Object{}
+		for _, pair := range obj.Pairs() {
+			elements = append(elements, pair.Key)
+		}
+		return elements, nil
+	case *object.String:
+		elements := []object.Object{}
+		for _, r := range obj.Value {
+			elements = append(elements, &object.String{Value: string(r)})
+		}
+		return elements, nil
+	default:
+		return nil, newError("argument to `%s` must be iterable, got %s", builtin, obj.Type())
+	}
+}
+
+//setAndElementArgs validates builtins called as fn(set, element)
+func setAndElementArgs(builtin string, args []object.Object) (*object.Set, object.Hashable, *object.Error) {
+	if err := checkArgCount(args, 2, 2); err != nil {
+		return nil, nil, err
+	}
+	set, ok := args[0].(*object.Set)
+	if !ok {
+		return nil, nil, newError("argument 1 to `%s` must be SET, got %s", builtin, args[0].Type())
+	}
+	element, ok := object.AsHashKey(args[1])
+	if !ok {
+		return nil, nil, newError("unusable as set element: %s", args[1].Type())
+	}
+	return set, element, nil
+}
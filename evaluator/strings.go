@@ -0,0 +1,350 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//maxStringLength is the longest string repeat and padding build, in bytes for repeat and
+// in characters for the padding width
+const maxStringLength = 1 << 28
+
+//stringBuiltins are available both as builtins taking the string as their first
+// argument and as methods on strings. eg. split("a,b", ",") or "a,b".split(",")
+// positions and lengths are counted in characters (runes), not bytes
+var stringBuiltins = map[string]*object.Builtin{
+	"split": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			s, err := stringArg("split", args, 0)
+			if err != nil {
+				return err
+			}
+			var parts []string
+			if len(args) == 1 {
+				parts = strings.Fields(s)
+			} else {
+				sep, err := stringArg("split", args, 1)
+				if err != nil {
+					return err
+				}
+				parts = strings.Split(s, sep)
+			}
+			return stringsToArray(parts)
+		},
+	},
+	"join": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 2); err != nil {
+				return err
+			}
+			// accept both join(array, sep) and sep.join(array)
+			if args[0].Type() == object.STRING_OBJ {
+				args = []object.Object{args[1], args[0]}
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("argument to `join` must be ARRAY, got %s", args[0].Type())
+			}
+			sep, err := stringArg("join", args, 1)
+			if err != nil {
+				return err
+			}
+			parts := make([]string, len(arr.Elements))
+			for i, element := range arr.Elements {
+				parts[i] = element.Inspect()
+			}
+			return &object.String{Value: strings.Join(parts, sep)}
+		},
+	},
+	"trim": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			s, err := stringArg("trim", args, 0)
+			if err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				return &object.String{Value: strings.TrimSpace(s)}
+			}
+			cutset, err := stringArg("trim", args, 1)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: strings.Trim(s, cutset)}
+		},
+	},
+	"upper": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			s, err := stringArg("upper", args, 0)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: strings.ToUpper(s)}
+		},
+	},
+	"lower": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			s, err := stringArg("lower", args, 0)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: strings.ToLower(s)}
+		},
+	},
+	"replace": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 3, 4); err != nil {
+				return err
+			}
+			s, err := stringArg("replace", args, 0)
+			if err != nil {
+				return err
+			}
+			old, err := stringArg("replace", args, 1)
+			if err != nil {
+				return err
+			}
+			replacement, err := stringArg("replace", args, 2)
+			if err != nil {
+				return err
+			}
+			count := int64(-1)
+			if len(args) == 4 {
+				if count, err = integerArg("replace", args, 3); err != nil {
+					return err
+				}
+			}
+			return &object.String{Value: strings.Replace(s, old, replacement, int(count))}
+		},
+	},
+	"startsWith": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			s, prefix, err := twoStringArgs("startsWith", args)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(strings.HasPrefix(s, prefix))
+		},
+	},
+	"endsWith": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			s, suffix, err := twoStringArgs("endsWith", args)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(strings.HasSuffix(s, suffix))
+		},
+	},
+	"contains": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			s, sub, err := twoStringArgs("contains", args)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(strings.Contains(s, sub))
+		},
+	},
+	"indexOf": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			s, sub, err := twoStringArgs("indexOf", args)
+			if err != nil {
+				return err
+			}
+			index := strings.Index(s, sub)
+			if index < 0 {
+				return &object.Integer{Value: -1}
+			}
+			return &object.Integer{Value: int64(utf8.RuneCountInString(s[:index]))}
+		},
+	},
+	"repeat": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 2, 2); err != nil {
+				return err
+			}
+			s, err := stringArg("repeat", args, 0)
+			if err != nil {
+				return err
+			}
+			count, err := integerArg("repeat", args, 1)
+			if err != nil {
+				return err
+			}
+			if count < 0 {
+				return newError("argument to `repeat` must not be negative, got %d", count)
+			}
+			if len(s) > 0 && count > maxStringLength/int64(len(s)) {
+				return newError("result of `repeat` would be longer than %d bytes", maxStringLength)
+			}
+			return &object.String{Value: strings.Repeat(s, int(count))}
+		},
+	},
+	"padLeft": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return padString("padLeft", args, true)
+		},
+	},
+	"padRight": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return padString("padRight", args, false)
+		},
+	},
+	"chars": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			s, err := stringArg("chars", args, 0)
+			if err != nil {
+				return err
+			}
+			chars := []string{}
+			for _, r := range s {
+				chars = append(chars, string(r))
+			}
+			return stringsToArray(chars)
+		},
+	},
+	"format": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 1 {
+				return newError("wrong number of arguments. got=%d, want at least 1", len(args))
+			}
+			s, err := stringArg("format", args, 0)
+			if err != nil {
+				return err
+			}
+			return formatString(s, args[1:])
+		},
+	},
+}
+
+func init() {
+	registerBuiltinMethods(object.STRING_OBJ, stringBuiltins)
+}
+
+//twoStringArgs validates builtins that take exactly two strings
+func twoStringArgs(builtin string, args []object.Object) (string, string, *object.Error) {
+	if err := checkArgCount(args, 2, 2); err != nil {
+		return "", "", err
+	}
+	first, err := stringArg(builtin, args, 0)
+	if err != nil {
+		return "", "", err
+	}
+	second, err := stringArg(builtin, args, 1)
+	if err != nil {
+		return "", "", err
+	}
+	return first, second, nil
+}
+
+//padString pads a string to a width counted in characters, using spaces
+// or the optional pad string
+func padString(builtin string, args []object.Object, left bool) object.Object {
+	if err := checkArgCount(args, 2, 3); err != nil {
+		return err
+	}
+	s, err := stringArg(builtin, args, 0)
+	if err != nil {
+		return err
+	}
+	width, err := integerArg(builtin, args, 1)
+	if err != nil {
+		return err
+	}
+	if width > maxStringLength {
+		return newError("width of `%s` must be at most %d", builtin, maxStringLength)
+	}
+	pad := " "
+	if len(args) == 3 {
+		if pad, err = stringArg(builtin, args, 2); err != nil {
+			return err
+		}
+		if pad == "" {
+			return newError("pad string to `%s` must not be empty", builtin)
+		}
+	}
+	padRunes := []rune(pad)
+	missing := int(width) - utf8.RuneCountInString(s)
+	var padding strings.Builder
+	for i := 0; i < missing; i++ {
+		padding.WriteRune(padRunes[i%len(padRunes)])
+	}
+	if left {
+		return &object.String{Value: padding.String() + s}
+	}
+	return &object.String{Value: s + padding.String()}
+}
+
+//formatString replaces the placeholders of a format string with the arguments.
+// {} takes the next argument, {n} the argument at position n, {{ and }} are literal braces
+func formatString(format string, args []object.Object) object.Object {
+	var out strings.Builder
+	next := 0
+	runes := []rune(format)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		if ch == '}' {
+			if i+1 < len(runes) && runes[i+1] == '}' {
+				i++
+			}
+			out.WriteRune('}')
+			continue
+		}
+		if ch != '{' {
+			out.WriteRune(ch)
+			continue
+		}
+		if i+1 < len(runes) && runes[i+1] == '{' {
+			out.WriteRune('{')
+			i++
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && runes[end] != '}' {
+			end++
+		}
+		if end == len(runes) {
+			return newError("unclosed placeholder in format string %q", format)
+		}
+		index := next
+		if field := string(runes[i+1 : end]); field != "" {
+			position, err := strconv.Atoi(field)
+			if err != nil {
+				return newError("invalid placeholder {%s} in format string", field)
+			}
+			index = position
+		} else {
+			next++
+		}
+		if index < 0 || index >= len(args) {
+			return newError("format string needs argument %d, got %d arguments", index, len(args))
+		}
+		out.WriteString(args[index].Inspect())
+		i = end
+	}
+	return &object.String{Value: out.String()}
+}
+
+//stringsToArray converts a slice of go strings into an Array of String objects
+func stringsToArray(values []string) *object.Array {
+	elements := make([]object.Object, len(values))
+	for i, value := range values {
+		elements[i] = &object.String{Value: value}
+	}
+	return &object.Array{Elements: elements}
+}
@@ -0,0 +1,588 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"monkey/object"
+	"strings"
+	"time"
+	// time zones work even on systems without a zoneinfo database
+	_ "time/tzdata"
+)
+
+//clockStart is the reference point of time.clock()
+var clockStart = time.Now()
+
+func init() {
+	registerModule("time", func() *object.Module {
+		members := map[string]object.Object{}
+		for name, builtin := range timeBuiltins {
+			members[name] = builtin
+		}
+		for name, unit := range durationUnits {
+			members[name] = durationConstructor(name, unit)
+		}
+		return newModule("time", members)
+	})
+	registerMethods(object.DATETIME_OBJ, dateTimeMethods)
+	registerMethods(object.DURATION_OBJ, durationMethods)
+}
+
+//timeBuiltins are the functions of the time module. date times are created in UTC unless
+// a time zone name from the tz database is given. eg. "Europe/Paris"
+var timeBuiltins = map[string]*object.Builtin{
+	// now returns the current date time in the local time zone or in tz
+	"now": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 0, 1); err != nil {
+				return err
+			}
+			now := time.Now()
+			if len(args) == 1 {
+				location, err := locationArg("now", args, 0)
+				if err != nil {
+					return err
+				}
+				now = now.In(location)
+			}
+			return &object.DateTime{Value: now}
+		},
+	},
+	// clock returns the seconds elapsed on a monotonic clock, for measuring durations
+	"clock": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 0, 0); err != nil {
+				return err
+			}
+			return &object.Float{Value: time.Since(clockStart).Seconds()}
+		},
+	},
+	// sleep pauses for a number of milliseconds or a duration
+	"sleep": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			if duration, ok := args[0].(*object.Duration); ok {
+				whileBlocked(func() { time.Sleep(duration.Value) })
+				return NULL
+			}
+			ms, err := numberArg("sleep", args, 0)
+			if err != nil {
+				return err
+			}
+			whileBlocked(func() { time.Sleep(time.Duration(ms * float64(time.Millisecond))) })
+			return NULL
+		},
+	},
+	// DateTime(year, month, day, hour, minute, second, tz) only needs the date
+	"DateTime": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 3, 7); err != nil {
+				return err
+			}
+			location := time.UTC
+			if tz, ok := args[len(args)-1].(*object.String); ok {
+				loaded, err := loadLocation(tz.Value)
+				if err != nil {
+					return err
+				}
+				location, args = loaded, args[:len(args)-1]
+			}
+			fields := []int{0, 1, 1, 0, 0, 0}
+			for i := range args {
+				if i == len(fields) {
+					return newError("too many arguments to `DateTime`")
+				}
+				field, err := integerArg("DateTime", args, i)
+				if err != nil {
+					return err
+				}
+				fields[i] = int(field)
+			}
+			value := time.Date(fields[0], time.Month(fields[1]), fields[2], fields[3], fields[4], fields[5], 0, location)
+			return &object.DateTime{Value: value}
+		},
+	},
+	"fromUnix": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 2); err != nil {
+				return err
+			}
+			seconds, err := numberArg("fromUnix", args, 0)
+			if err != nil {
+				return err
+			}
+			value := time.Unix(0, int64(seconds*float64(time.Second))).UTC()
+			if integer, ok := args[0].(*object.Integer); ok {
+				value = time.Unix(integer.Value, 0).UTC()
+			}
+			if len(args) == 2 {
+				location, err := locationArg("fromUnix", args, 1)
+				if err != nil {
+					return err
+				}
+				value = value.In(location)
+			}
+			return &object.DateTime{Value: value}
+		},
+	},
+	// parse(text, format, tz) reads RFC 3339 text without a format, or strftime style
+	// directives such as %Y-%m-%d. tz is used when the text has no offset
+	"parse": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 3); err != nil {
+				return err
+			}
+			text, err := stringArg("parse", args, 0)
+			if err != nil {
+				return err
+			}
+			location := time.UTC
+			if len(args) > 2 {
+				if location, err = locationArg("parse", args, 2); err != nil {
+					return err
+				}
+			}
+			if len(args) > 1 {
+				format, err := stringArg("parse", args, 1)
+				if err != nil {
+					return err
+				}
+				value, err := strptime(text, format, location)
+				if err != nil {
+					return err
+				}
+				return &object.DateTime{Value: value}
+			}
+			value, parseErr := time.ParseInLocation(time.RFC3339Nano, text, location)
+			if parseErr != nil {
+				return newError("could not parse %q as a date time", text)
+			}
+			return &object.DateTime{Value: value}
+		},
+	},
+	"parseDuration": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			text, err := stringArg("parseDuration", args, 0)
+			if err != nil {
+				return err
+			}
+			duration, parseErr := time.ParseDuration(text)
+			if parseErr != nil {
+				return newError("could not parse %q as a duration", text)
+			}
+			return &object.Duration{Value: duration}
+		},
+	},
+}
+
+//durationUnits are the duration constructors of the time module. eg. time.minutes(90)
+var durationUnits = map[string]time.Duration{
+	"milliseconds": time.Millisecond,
+	"seconds":      time.Second,
+	"minutes":      time.Minute,
+	"hours":        time.Hour,
+	"days":         24 * time.Hour,
+}
+
+//durationConstructor returns a builtin making a duration from a number of units
+func durationConstructor(builtin string, unit time.Duration) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args, 1, 1); err != nil {
+				return err
+			}
+			if integer, ok := args[0].(*object.Integer); ok {
+				return durationArithmetic("*", integer.Value, int64(unit))
+			}
+			n, err := numberArg(builtin, args, 0)
+			if err != nil {
+				return err
+			}
+			return floatDuration(n * float64(unit))
+		},
+	}
+}
+
+//dateTimeMethods are the methods of date times. eg. time.now().year()
+// the receiver is not counted in argument errors
+var dateTimeMethods = map[string]*object.Builtin{
+	"year":       dateTimeField(func(t time.Time) int { return t.Year() }),
+	"month":      dateTimeField(func(t time.Time) int { return int(t.Month()) }),
+	"day":        dateTimeField(func(t time.Time) int { return t.Day() }),
+	"hour":       dateTimeField(func(t time.Time) int { return t.Hour() }),
+	"minute":     dateTimeField(func(t time.Time) int { return t.Minute() }),
+	"second":     dateTimeField(func(t time.Time) int { return t.Second() }),
+	"nanosecond": dateTimeField(func(t time.Time) int { return t.Nanosecond() }),
+	"yearDay":    dateTimeField(func(t time.Time) int { return t.YearDay() }),
+	"weekday": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args[1:], 0, 0); err != nil {
+				return err
+			}
+			return &object.String{Value: args[0].(*object.DateTime).Value.Weekday().String()}
+		},
+	},
+	"unix": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args[1:], 0, 0); err != nil {
+				return err
+			}
+			return &object.Integer{Value: args[0].(*object.DateTime).Value.Unix()}
+		},
+	},
+	"zone": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args[1:], 0, 0); err != nil {
+				return err
+			}
+			return &object.String{Value: args[0].(*object.DateTime).Value.Location().String()}
+		},
+	},
+	// in returns the same instant in another time zone
+	"in": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args[1:], 1, 1); err != nil {
+				return err
+			}
+			location, err := locationArg("in", args[1:], 0)
+			if err != nil {
+				return err
+			}
+			return &object.DateTime{Value: args[0].(*object.DateTime).Value.In(location)}
+		},
+	},
+	"format": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args[1:], 1, 1); err != nil {
+				return err
+			}
+			format, err := stringArg("format", args[1:], 0)
+			if err != nil {
+				return err
+			}
+			text, err := strftime(args[0].(*object.DateTime).Value, format)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: text}
+		},
+	},
+	"rfc3339": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args[1:], 0, 0); err != nil {
+				return err
+			}
+			return &object.String{Value: args[0].Inspect()}
+		},
+	},
+}
+
+//dateTimeField returns a method reading an integer field of a date time
+func dateTimeField(field func(time.Time) int) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args[1:], 0, 0); err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(field(args[0].(*object.DateTime).Value))}
+		},
+	}
+}
+
+//durationMethods are the methods of durations. eg. time.minutes(90).hours() is 1.5
+var durationMethods = map[string]*object.Builtin{
+	"hours":   durationIn(time.Hour),
+	"minutes": durationIn(time.Minute),
+	"seconds": durationIn(time.Second),
+	"milliseconds": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args[1:], 0, 0); err != nil {
+				return err
+			}
+			return &object.Integer{Value: args[0].(*object.Duration).Value.Milliseconds()}
+		},
+	},
+}
+
+//durationIn returns a method giving the length of a duration in unit as a float
+func durationIn(unit time.Duration) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(args[1:], 0, 0); err != nil {
+				return err
+			}
+			return &object.Float{Value: float64(args[0].(*object.Duration).Value) / float64(unit)}
+		},
+	}
+}
+
+//evalTimeInfixExpression evaluates arithmetic and comparisons of date times and durations.
+// date time - date time gives a duration, date time ± duration gives a date time and
+// durations can be added together or scaled by numbers
+func evalTimeInfixExpression(operator string, left, right object.Object) object.Object {
+	switch l := left.(type) {
+	case *object.DateTime:
+		switch r := right.(type) {
+		case *object.Duration:
+			switch operator {
+			case "+":
+				return &object.DateTime{Value: l.Value.Add(r.Value)}
+			case "-":
+				return &object.DateTime{Value: l.Value.Add(-r.Value)}
+			}
+		case *object.DateTime:
+			switch operator {
+			case "-":
+				return &object.Duration{Value: l.Value.Sub(r.Value)}
+			case "<":
+				return nativeBoolToBooleanObject(l.Value.Before(r.Value))
+			case ">":
+				return nativeBoolToBooleanObject(l.Value.After(r.Value))
+			case "==":
+				return nativeBoolToBooleanObject(l.Value.Equal(r.Value))
+			case "!=":
+				return nativeBoolToBooleanObject(!l.Value.Equal(r.Value))
+			}
+		}
+	case *object.Duration:
+		switch r := right.(type) {
+		case *object.DateTime:
+			if operator == "+" {
+				return &object.DateTime{Value: r.Value.Add(l.Value)}
+			}
+		case *object.Duration:
+			switch operator {
+			case "+", "-":
+				return durationArithmetic(operator, int64(l.Value), int64(r.Value))
+			case "/":
+				if r.Value == 0 {
+					return newError("division by zero")
+				}
+				return &object.Float{Value: float64(l.Value) / float64(r.Value)}
+			case "<":
+				return nativeBoolToBooleanObject(l.Value < r.Value)
+			case ">":
+				return nativeBoolToBooleanObject(l.Value > r.Value)
+			case "==":
+				return nativeBoolToBooleanObject(l.Value == r.Value)
+			case "!=":
+				return nativeBoolToBooleanObject(l.Value != r.Value)
+			}
+		case *object.Integer:
+			switch operator {
+			case "*":
+				return durationArithmetic(operator, int64(l.Value), r.Value)
+			case "/":
+				if r.Value == 0 {
+					return newError("division by zero")
+				}
+				if r.Value == -1 {
+					return durationArithmetic("*", int64(l.Value), -1)
+				}
+				return &object.Duration{Value: l.Value / time.Duration(r.Value)}
+			}
+		case *object.Float:
+			switch operator {
+			case "*":
+				return floatDuration(float64(l.Value) * r.Value)
+			case "/":
+				if r.Value == 0 {
+					return newError("division by zero")
+				}
+				return floatDuration(float64(l.Value) / r.Value)
+			}
+		}
+	case *object.Integer, *object.Float:
+		if r, ok := right.(*object.Duration); ok && operator == "*" {
+			return evalTimeInfixExpression(operator, r, l)
+		}
+	}
+	switch operator {
+	case "==":
+		return FALSE
+	case "!=":
+		return TRUE
+	}
+	if left.Type() != right.Type() {
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+	return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+//durationArithmetic applies +, - or * to durations in nanoseconds, failing instead of wrapping around
+func durationArithmetic(operator string, a, b int64) object.Object {
+	value, ok := int64Arithmetic(operator, a, b)
+	if !ok {
+		return newError("duration out of range")
+	}
+	return &object.Duration{Value: time.Duration(value)}
+}
+
+//floatDuration returns the duration of a number of nanoseconds, or an error when it does not fit in one
+func floatDuration(nanoseconds float64) object.Object {
+	if math.IsNaN(nanoseconds) || nanoseconds >= math.MaxInt64 || nanoseconds < math.MinInt64 {
+		return newError("duration out of range")
+	}
+	return &object.Duration{Value: time.Duration(nanoseconds)}
+}
+
+//isTimeObject checks whether obj is a date time or a duration
+func isTimeObject(obj object.Object) bool {
+	return obj.Type() == object.DATETIME_OBJ || obj.Type() == object.DURATION_OBJ
+}
+
+//strftimeDirectives maps strftime directives to the matching part of a Go time layout
+var strftimeDirectives = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'e': "_2",
+	'j': "002",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'p': "PM",
+	'b': "Jan",
+	'B': "January",
+	'a': "Mon",
+	'A': "Monday",
+	'z': "-0700",
+	'Z': "MST",
+}
+
+//strftime formats t with strftime style directives. eg. %Y-%m-%d %H:%M:%S
+// %f is the microsecond and %% a literal percent sign
+func strftime(t time.Time, format string) (string, *object.Error) {
+	var out strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			out.WriteByte(format[i])
+			continue
+		}
+		if i+1 == len(format) {
+			return "", newError("format %q ends with an incomplete directive", format)
+		}
+		i++
+		switch directive := format[i]; directive {
+		case '%':
+			out.WriteByte('%')
+		case 'f':
+			fmt.Fprintf(&out, "%06d", t.Nanosecond()/1000)
+		default:
+			layout, ok := strftimeDirectives[directive]
+			if !ok {
+				return "", newError("unknown directive %%%c in format %q", directive, format)
+			}
+			out.WriteString(t.Format(layout))
+		}
+	}
+	return out.String(), nil
+}
+
+//strptimeWidths is the most digits each numeric directive reads when parsing
+var strptimeWidths = map[byte]int{
+	'Y': 4, 'y': 2, 'm': 2, 'd': 2, 'e': 2, 'j': 3, 'H': 2, 'I': 2, 'M': 2, 'S': 2, 'z': 4,
+}
+
+//strptime parses text with a strftime style format. literal text in the format has to
+// appear as is in text, and every directive is cut out of text and handed to Go on its own
+// so that literals such as "day 2" are never mistaken for layout fields
+func strptime(text, format string, location *time.Location) (time.Time, *object.Error) {
+	failed := newError("could not parse %q as a date time", text)
+	var layout, value []string
+	var fraction time.Duration
+	pos := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || (i+1 < len(format) && format[i+1] == '%') {
+			if format[i] == '%' {
+				i++
+			}
+			if pos == len(text) || text[pos] != format[i] {
+				return time.Time{}, failed
+			}
+			pos++
+			continue
+		}
+		if i+1 == len(format) {
+			return time.Time{}, newError("format %q ends with an incomplete directive", format)
+		}
+		i++
+		directive := format[i]
+		part, ok := strftimeDirectives[directive]
+		if !ok && directive != 'f' {
+			return time.Time{}, newError("unknown directive %%%c in format %q", directive, format)
+		}
+		start := pos
+		switch width, numeric := strptimeWidths[directive]; {
+		case directive == 'f':
+			for pos < len(text) && isDigitByte(text[pos]) {
+				pos++
+			}
+		case numeric:
+			if pos < len(text) && (directive == 'e' && text[pos] == ' ' || directive == 'z' && (text[pos] == '+' || text[pos] == '-')) {
+				pos++
+			}
+			for digits := 0; digits < width && pos < len(text) && isDigitByte(text[pos]); digits++ {
+				pos++
+			}
+		default:
+			for pos < len(text) && isLetterByte(text[pos]) {
+				pos++
+			}
+		}
+		if pos == start {
+			return time.Time{}, failed
+		}
+		if directive == 'f' {
+			// any number of fractional digits is accepted, only the first nine count
+			digits := (text[start:pos] + "000000000")[:9]
+			for _, digit := range digits {
+				fraction = fraction*10 + time.Duration(digit-'0')
+			}
+			continue
+		}
+		layout = append(layout, part)
+		value = append(value, text[start:pos])
+	}
+	if pos != len(text) {
+		return time.Time{}, failed
+	}
+	// the fields are joined by a byte that no layout element or field contains
+	parsed, err := time.ParseInLocation(strings.Join(layout, "\x00"), strings.Join(value, "\x00"), location)
+	if err != nil {
+		return time.Time{}, failed
+	}
+	return parsed.Add(fraction), nil
+}
+
+func isDigitByte(ch byte) bool {
+	return '0' <= ch && ch <= '9'
+}
+
+func isLetterByte(ch byte) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z'
+}
+
+//locationArg returns the time zone named by the argument at index i
+func locationArg(builtin string, args []object.Object, i int) (*time.Location, *object.Error) {
+	name, err := stringArg(builtin, args, i)
+	if err != nil {
+		return nil, err
+	}
+	return loadLocation(name)
+}
+
+func loadLocation(name string) (*time.Location, *object.Error) {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, newError("unknown time zone %s", name)
+	}
+	return location, nil
+}
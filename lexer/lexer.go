@@ -1,16 +1,20 @@
 package lexer
 
-import "monkey/token"
+import (
+	"monkey/token"
+	"strings"
+)
 
 //Lexer : The lexer struct
 type Lexer struct {
 	input        string
 	lineNo       int
 	charNo       int
-	position     int  // current position in input (points to current char)
-	readPosition int  // current reading position in input (after current char)
-	ch           byte // current char under examination
-
+	position     int             // current position in input (points to current char)
+	readPosition int             // current reading position in input (after current char)
+	ch           byte            // current char under examination
+	prev         token.TokenType // type of the last token returned, to tell regex literals from division
+	prevLineNo   int             // line of the last token returned
 }
 
 //readChar : Read the current character into ch of lexer
@@ -28,6 +32,30 @@ func (l *Lexer) readChar() {
 
 //NextToken : read and return the next token
 func (l *Lexer) NextToken() token.Token {
+	tok := l.nextToken()
+	l.prev, l.prevLineNo = tok.Type, l.lineNo
+	return tok
+}
+
+//regexFlags : the flags a regex literal may end with, the ones the regex builtins compile
+const regexFlags = "imsU"
+
+//endsOperand : tokens after which a slash divides instead of starting a regex literal
+var endsOperand = map[token.TokenType]bool{
+	token.IDENT:    true,
+	token.INT:      true,
+	token.FLOAT:    true,
+	token.STRING:   true,
+	token.BYTES:    true,
+	token.REGEX:    true,
+	token.TRUE:     true,
+	token.FALSE:    true,
+	token.NULL:     true,
+	token.RPAREN:   true,
+	token.RBRACKET: true,
+}
+
+func (l *Lexer) nextToken() token.Token {
 	var tok token.Token
 
 	l.skipWhitespace()
@@ -57,6 +85,10 @@ func (l *Lexer) NextToken() token.Token {
 
 	case '*':
 		tok = newToken(token.ASTERISK, l.ch, l.charNo, l.lineNo)
+	case '|':
+		tok = newToken(token.PIPE, l.ch, l.charNo, l.lineNo)
+	case '&':
+		tok = newToken(token.AMPERSAND, l.ch, l.charNo, l.lineNo)
 	case '/':
 		tok = newToken(token.SLASH, l.ch, l.charNo, l.lineNo)
 	case '=':
@@ -64,6 +96,10 @@ func (l *Lexer) NextToken() token.Token {
 			ch := l.ch
 			l.readChar()
 			tok = token.Token{Type: token.EQ, Literal: string(ch) + string(l.ch), CharNo: l.charNo, LineNo: l.lineNo}
+		} else if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.ARROW, Literal: string(ch) + string(l.ch), CharNo: l.charNo, LineNo: l.lineNo}
 		} else {
 			tok = newToken(token.ASSIGN, l.ch, l.charNo, l.lineNo)
 		}
@@ -104,7 +140,21 @@ func (l *Lexer) NextToken() token.Token {
 		tok.Literal = ""
 		tok.Type = token.EOF
 	default:
-		if isLetter(l.ch) {
+		// r/ only starts a regex where an operand is expected or on a new line, since semicolons
+		// are optional, and only if the literal is complete. otherwise r is a name being divided
+		if l.ch == 'r' && l.peekChar() == '/' && (!endsOperand[l.prev] || l.lineNo != l.prevLineNo) {
+			start := *l
+			if tok.Literal = l.readRegex(); tok.Literal != "" {
+				tok.Type = token.REGEX
+				return tok
+			}
+			*l = start
+		}
+		if l.ch == 'b' && l.peekChar() == '"' {
+			tok.Type = token.BYTES
+			tok.Literal = l.readBytes()
+			return tok
+		} else if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
 			return tok
@@ -174,6 +224,56 @@ func (l *Lexer) readString() string {
 	return l.input[position:l.position]
 }
 
+//readBytes reads a bytes literal such as b"\x00\"", returning what is between the quotes.
+// unlike strings, bytes literals have escape sequences, which the parser decodes
+func (l *Lexer) readBytes() string {
+	l.readChar() // the b
+	position := l.position + 1
+	for {
+		l.readChar()
+		if l.ch == '\\' && l.peekChar() != 0 {
+			l.readChar()
+			continue
+		}
+		if l.ch == '"' || l.ch == 0 {
+			break
+		}
+	}
+	literal := l.input[position:l.position]
+	l.readChar()
+	return literal
+}
+
+//readRegex reads a regex literal such as r/\d+/i, returning it whole.
+// a slash inside the pattern is written \/ and the letters after the closing slash
+// are flags. an unterminated literal, or one running into a name or a number, returns ""
+func (l *Lexer) readRegex() string {
+	position := l.position
+	l.readChar() // the opening slash
+	for {
+		l.readChar()
+		if l.ch == 0 || l.ch == '\n' {
+			return ""
+		}
+		if l.ch == '\\' && l.peekChar() != 0 {
+			l.readChar()
+			continue
+		}
+		if l.ch == '/' {
+			break
+		}
+	}
+	l.readChar()
+	for l.ch != 0 && strings.IndexByte(regexFlags, l.ch) >= 0 {
+		l.readChar()
+	}
+	if isLetter(l.ch) || isDigit(l.ch) {
+		// r/w/1 and r/b/c divide r by w and then by 1 or c
+		return ""
+	}
+	return l.input[position:l.position]
+}
+
 //skipWhitespace : Helper function to go to next char if current char
 // is character without value to us such ar \r,\n, space, \t
 func (l *Lexer) skipWhitespace() {
@@ -28,6 +28,13 @@ if (5 < 10) {
 
 10 == 10;
 10 != 9;
+match (x) { _ => 1 }
+a | b & c in d
+r/a\/b+/i.match(r / 2)
+b"\x00\"" b[1:]
+let q = r/w/1; r/2
+let a = r/b/c;
+x = r/b/c
 `
 
 	tests := []struct {
@@ -117,6 +124,64 @@ if (5 < 10) {
 		{token.NOT_EQ, "!="},
 		{token.INT, "9"},
 		{token.SEMICOLON, ";"},
+		{token.MATCH, "match"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.IDENT, "a"},
+		{token.PIPE, "|"},
+		{token.IDENT, "b"},
+		{token.AMPERSAND, "&"},
+		{token.IDENT, "c"},
+		{token.IN, "in"},
+		{token.IDENT, "d"},
+		{token.REGEX, `r/a\/b+/i`},
+		{token.DOT, "."},
+		{token.MATCH, "match"},
+		{token.LPAREN, "("},
+		{token.IDENT, "r"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.RPAREN, ")"},
+		{token.BYTES, `\x00\"`},
+		{token.IDENT, "b"},
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COLON, ":"},
+		{token.RBRACKET, "]"},
+		{token.LET, "let"},
+		{token.IDENT, "q"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "r"},
+		{token.SLASH, "/"},
+		{token.IDENT, "w"},
+		{token.SLASH, "/"},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "r"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.LET, "let"},
+		{token.IDENT, "a"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "r"},
+		{token.SLASH, "/"},
+		{token.IDENT, "b"},
+		{token.SLASH, "/"},
+		{token.IDENT, "c"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "r"},
+		{token.SLASH, "/"},
+		{token.IDENT, "b"},
+		{token.SLASH, "/"},
+		{token.IDENT, "c"},
 		{token.EOF, ""},
 	}
 
@@ -21,14 +21,22 @@ func main() {
 	fmt.Printf("Hello %s! This is the Monkey programming language!\n",
 		user.Username)
 	fmt.Printf("Feel free to type in commands\n")
-	if len(os.Args) > 1 {
-		filename := os.Args[1]
+	args := os.Args[1:]
+	// --sandbox keeps scripts from running other programs
+	if len(args) > 0 && args[0] == "--sandbox" {
+		evaluator.Sandbox = true
+		args = args[1:]
+	}
+	if len(args) > 0 {
+		filename := args[0]
 		content, err := ioutil.ReadFile(filename)
 		if err != nil {
 			fmt.Printf("Could not open file : %s", filename)
 			panic(err)
 		}
 		env := object.NewEnvironment()
+		// the script sees its path and everything after it as os.args
+		evaluator.SetArgs(args)
 
 		l := lexer.New(string(content))
 		p := parser.New(l)
@@ -54,7 +62,7 @@ func main() {
 		}
 
 	} else {
-		repl.Start(os.Stdin, os.Stdout)
+		repl.Start(evaluator.Stdin(), os.Stdout)
 	}
 }
 
@@ -1,9 +1,12 @@
 package object
 
+import "sort"
+
 //Environment Holds environments in the interpreter
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store    map[string]Object
+	outer    *Environment
+	receiver Object // the instance whose method runs in this environment, not reachable by name
 }
 
 //SetOuter sets a value for the outer field of an environment
@@ -36,6 +39,21 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return obj, ok
 }
 
+//SetReceiver marks the environment as the body of a method called on receiver
+func (e *Environment) SetReceiver(receiver Object) {
+	e.receiver = receiver
+}
+
+//Receiver returns the receiver of the innermost method call the environment is part of
+func (e *Environment) Receiver() (Object, bool) {
+	for env := e; env != nil; env = env.outer {
+		if env.receiver != nil {
+			return env.receiver, true
+		}
+	}
+	return nil, false
+}
+
 //Closed makes a copy of the environment excluding the outer
 func (e *Environment) Closed() *Environment {
 	return &Environment{store: e.store, outer: nil}
@@ -47,6 +65,26 @@ func (e *Environment) Set(name string, val Object) Object {
 	return val
 }
 
+//Delete removes an entry from the environment's own store.
+// It reports whether the entry existed
+func (e *Environment) Delete(name string) bool {
+	if _, ok := e.store[name]; !ok {
+		return false
+	}
+	delete(e.store, name)
+	return true
+}
+
+//Names returns the sorted names defined in the environment's own store
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 //SetMultiple sets multiple key values to evironment's store
 func (e *Environment) SetMultiple(values map[string]Object) *Environment {
 	for key, value := range values {
@@ -4,27 +4,48 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"io"
+	"math"
+	"math/big"
 	"monkey/ast"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type ObjectType string
 
 const (
-	INTEGER_OBJ       = "INTEGER"
-	FLOAT_OBJ         = "FLOAT"
-	BOOOLEAN_OBJ      = "BOOLEAN"
-	NULL_OBJ          = "NULL"
-	RETURN_VALUE_OBJ  = "RETURN_VALUE"
-	FUNCTION_OBJ      = "FUNCTION"
-	STRING_OBJ        = "STRING"
-	ERROR_OBJ         = "ERROR"
-	BUILTIN_OBJ       = "BUILTIN"
-	ARRAY_OBJ         = "ARRAY"
-	HASH_OBJ          = "HASH"
-	CLASS_OBJ         = "CLASS"
-	CLASSINSTANCE_OBJ = "CLASS_INSTANCE"
-	MODULE_OBJ        = "MODULE"
+	INTEGER_OBJ        = "INTEGER"
+	FLOAT_OBJ          = "FLOAT"
+	BOOOLEAN_OBJ       = "BOOLEAN"
+	NULL_OBJ           = "NULL"
+	RETURN_VALUE_OBJ   = "RETURN_VALUE"
+	FUNCTION_OBJ       = "FUNCTION"
+	STRING_OBJ         = "STRING"
+	ERROR_OBJ          = "ERROR"
+	BUILTIN_OBJ        = "BUILTIN"
+	ARRAY_OBJ          = "ARRAY"
+	HASH_OBJ           = "HASH"
+	CLASS_OBJ          = "CLASS"
+	CLASSINSTANCE_OBJ  = "CLASS_INSTANCE"
+	MODULE_OBJ         = "MODULE"
+	TYPE_OBJ           = "TYPE"
+	INTERFACE_OBJ      = "INTERFACE"
+	ENUM_OBJ           = "ENUM"
+	ENUMMEMBER_OBJ     = "ENUM_MEMBER"
+	RECORD_OBJ         = "RECORD"
+	RECORDINSTANCE_OBJ = "RECORD_INSTANCE"
+	BOUNDMETHOD_OBJ    = "BOUND_METHOD"
+	SET_OBJ            = "SET"
+	TUPLE_OBJ          = "TUPLE"
+	BIGINT_OBJ         = "BIGINT"
+	DECIMAL_OBJ        = "DECIMAL"
+	DATETIME_OBJ       = "DATETIME"
+	DURATION_OBJ       = "DURATION"
+	REGEX_OBJ          = "REGEX"
+	BYTES_OBJ          = "BYTES"
 )
 
 type Object interface {
@@ -35,9 +56,35 @@ type Object interface {
 //BuiltinFunction a function representation of builtin functions
 type BuiltinFunction func(args ...Object) Object
 
-//Hashable interface for object that implement a HashKey function
+//Hashable interface for object that implement a HashKey function.
+// different keys may share a HashKey, Equals tells them apart
 type Hashable interface {
+	Object
 	HashKey() HashKey
+	Equals(other Object) bool
+}
+
+//AsHashKey returns obj as a Hashable when it can be used as a hash key or set element.
+// tuples and records only can when every element can: arrays and hashes compare by
+// identity and may change after insertion, so a key holding one could never be found
+func AsHashKey(obj Object) (Hashable, bool) {
+	hashable, ok := obj.(Hashable)
+	if !ok {
+		return nil, false
+	}
+	var elements []Object
+	switch obj := obj.(type) {
+	case *Tuple:
+		elements = obj.Elements
+	case *RecordInstance:
+		elements = obj.Values
+	}
+	for _, element := range elements {
+		if _, ok := AsHashKey(element); !ok {
+			return nil, false
+		}
+	}
+	return hashable, true
 }
 
 //Integer object to hold integers
@@ -53,7 +100,126 @@ func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 
 //HashKey function to generate a HashKey object from a Integer
 func (i *Integer) HashKey() HashKey {
-	return HashKey{Type: i.Type(), Value: float64(i.Value)}
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+//Equals reports whether other is an Integer with the same value
+func (i *Integer) Equals(other Object) bool {
+	o, ok := other.(*Integer)
+	return ok && o.Value == i.Value
+}
+
+//BigInt object to hold integers that do not fit in an int64.
+// the evaluator only creates a BigInt for values outside the int64 range
+type BigInt struct {
+	Value *big.Int
+}
+
+//Inspect returns a string representation of the object
+func (b *BigInt) Inspect() string { return b.Value.String() }
+
+//Type returns the type of the object
+func (b *BigInt) Type() ObjectType { return BIGINT_OBJ }
+
+//HashKey function to generate a HashKey object from a BigInt
+func (b *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(b.Value.String()))
+	return HashKey{Type: b.Type(), Value: h.Sum64()}
+}
+
+//Equals reports whether other is a BigInt with the same value
+func (b *BigInt) Equals(other Object) bool {
+	o, ok := other.(*BigInt)
+	return ok && o.Value.Cmp(b.Value) == 0
+}
+
+//Decimal object to hold exact decimal numbers. eg. 12.50 is {Unscaled: 1250, Scale: 2}
+type Decimal struct {
+	Unscaled *big.Int
+	Scale    int
+}
+
+//ParseDecimal parses a decimal number such as -12.50
+func ParseDecimal(s string) (*Decimal, error) {
+	digits := strings.TrimLeft(s, "+-")
+	if len(s)-len(digits) > 1 || digits == "" {
+		return nil, fmt.Errorf("could not parse %q as decimal", s)
+	}
+	scale := 0
+	if dot := strings.Index(digits, "."); dot >= 0 {
+		scale = len(digits) - dot - 1
+		digits = digits[:dot] + digits[dot+1:]
+	}
+	unscaled, ok := new(big.Int).SetString(digits, 10)
+	if !ok || strings.ContainsAny(digits, "+-") {
+		return nil, fmt.Errorf("could not parse %q as decimal", s)
+	}
+	if strings.HasPrefix(s, "-") {
+		unscaled.Neg(unscaled)
+	}
+	return &Decimal{Unscaled: unscaled, Scale: scale}, nil
+}
+
+//Inspect returns a string representation of the object
+func (d *Decimal) Inspect() string {
+	digits := new(big.Int).Abs(d.Unscaled).String()
+	if d.Scale > 0 {
+		if len(digits) <= d.Scale {
+			digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.Scale] + "." + digits[len(digits)-d.Scale:]
+	}
+	if d.Unscaled.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+//Type returns the type of the object
+func (d *Decimal) Type() ObjectType { return DECIMAL_OBJ }
+
+//Rescale returns the unscaled value of d at a larger scale
+func (d *Decimal) Rescale(scale int) *big.Int {
+	factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale-d.Scale)), nil)
+	return factor.Mul(factor, d.Unscaled)
+}
+
+//Cmp compares d and other, returning -1, 0 or +1
+func (d *Decimal) Cmp(other *Decimal) int {
+	scale := d.Scale
+	if other.Scale > scale {
+		scale = other.Scale
+	}
+	return d.Rescale(scale).Cmp(other.Rescale(scale))
+}
+
+//Trim removes trailing fractional zeros, keeping at least minScale decimal places
+func (d *Decimal) Trim(minScale int) *Decimal {
+	unscaled, scale := new(big.Int).Set(d.Unscaled), d.Scale
+	ten, remainder := big.NewInt(10), new(big.Int)
+	for scale > minScale {
+		quotient, r := new(big.Int).QuoRem(unscaled, ten, remainder)
+		if r.Sign() != 0 {
+			break
+		}
+		unscaled, scale = quotient, scale-1
+	}
+	return &Decimal{Unscaled: unscaled, Scale: scale}
+}
+
+//HashKey function to generate a HashKey object from a Decimal.
+// equal decimals with different scales such as 1.5 and 1.50 share a HashKey
+func (d *Decimal) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(d.Trim(0).Inspect()))
+	return HashKey{Type: d.Type(), Value: h.Sum64()}
+}
+
+//Equals reports whether other is a Decimal with the same value
+func (d *Decimal) Equals(other Object) bool {
+	o, ok := other.(*Decimal)
+	return ok && d.Cmp(o) == 0
 }
 
 //Float object to hold integers
@@ -61,15 +227,49 @@ type Float struct {
 	Value float64
 }
 
-//Inspect returns a string representation of the object
-func (f *Float) Inspect() string { return fmt.Sprintf("%f", f.Value) }
+//Inspect returns the shortest representation that parses back to the same float.
+// floats always show a decimal point or an exponent. eg. 0.1, 3.0, 1e-09, inf, nan
+func (f *Float) Inspect() string {
+	switch {
+	case math.IsNaN(f.Value):
+		return "nan"
+	case math.IsInf(f.Value, 1):
+		return "inf"
+	case math.IsInf(f.Value, -1):
+		return "-inf"
+	}
+	scientific := strconv.FormatFloat(f.Value, 'e', -1, 64)
+	exponent, _ := strconv.Atoi(scientific[strings.Index(scientific, "e")+1:])
+	if exponent < -4 || exponent >= 16 {
+		return scientific
+	}
+	s := strconv.FormatFloat(f.Value, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
 
 //Type returns the type of the object
 func (f *Float) Type() ObjectType { return FLOAT_OBJ }
 
-//HashKey function to generate a HashKey object from a Integer
+//HashKey function to generate a HashKey object from a Float
 func (f *Float) HashKey() HashKey {
-	return HashKey{Type: f.Type(), Value: float64(f.Value)}
+	if f.Value == 0 {
+		// 0.0 and -0.0 are equal keys
+		return HashKey{Type: f.Type(), Value: 0}
+	}
+	if math.IsNaN(f.Value) {
+		return HashKey{Type: f.Type(), Value: math.Float64bits(math.NaN())}
+	}
+	return HashKey{Type: f.Type(), Value: math.Float64bits(f.Value)}
+}
+
+//Equals reports whether other is a Float with the same value.
+// unlike the == operator, NaN equals NaN so that it can be found again as a hash key
+func (f *Float) Equals(other Object) bool {
+	o, ok := other.(*Float)
+	return ok && (o.Value == f.Value || (math.IsNaN(o.Value) && math.IsNaN(f.Value)))
 }
 
 //Boolean object to hold boolean values true and false
@@ -85,7 +285,7 @@ func (b *Boolean) Type() ObjectType { return BOOOLEAN_OBJ }
 
 //HashKey function to generate a HashKey object from a boolean
 func (b *Boolean) HashKey() HashKey {
-	var value float64
+	var value uint64
 	if b.Value {
 		value = 1
 	} else {
@@ -94,6 +294,12 @@ func (b *Boolean) HashKey() HashKey {
 	return HashKey{Type: b.Type(), Value: value}
 }
 
+//Equals reports whether other is a Boolean with the same value
+func (b *Boolean) Equals(other Object) bool {
+	o, ok := other.(*Boolean)
+	return ok && o.Value == b.Value
+}
+
 //Null struct representation of null values
 type Null struct{}
 
@@ -164,11 +370,17 @@ func (s *String) Type() ObjectType { return STRING_OBJ }
 //Inspect returns the value of the object
 func (s *String) Inspect() string { return s.Value }
 
-//HashKey function to generate a HashKey object from a boolean
+//HashKey function to generate a HashKey object from a String
 func (s *String) HashKey() HashKey {
 	h := fnv.New64a()
 	h.Write([]byte(s.Value))
-	return HashKey{Type: s.Type(), Value: float64(h.Sum64())}
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+//Equals reports whether other is a String with the same value
+func (s *String) Equals(other Object) bool {
+	o, ok := other.(*String)
+	return ok && o.Value == s.Value
 }
 
 //Builtin node representation of all builtin functions and objects
@@ -204,10 +416,19 @@ func (ao *Array) Inspect() string {
 	return out.String()
 }
 
-//HashKey node representation of hash objects keys
+//HashKey node representation of hash objects keys.
+// keys are not unique, a Hash compares colliding keys with Hashable.Equals
 type HashKey struct {
 	Type  ObjectType
-	Value float64
+	Value uint64
+}
+
+//valuesEqual compares hashable objects by value and any other object by identity
+func valuesEqual(a, b Object) bool {
+	if hashable, ok := a.(Hashable); ok {
+		return hashable.Equals(b)
+	}
+	return a == b
 }
 
 //HashPair a pair of entries in a hashmap
@@ -216,19 +437,123 @@ type HashPair struct {
 	Value Object
 }
 
-//Hash a hashmap/dictionary/map implementation
+//Hash a hashmap/dictionary/map implementation.
+// pairs are kept in insertion order so that Inspect and iteration are stable.
+// index maps a HashKey to the positions of every pair whose key has that HashKey.
+// deleting leaves a pair with a nil Key in place so that no other position moves;
+// deleted counts those and they are compacted away once they make up half of pairs
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	pairs   []HashPair
+	index   map[HashKey][]int
+	deleted int
+}
+
+//NewHash creates an empty Hash
+func NewHash() *Hash {
+	return &Hash{index: make(map[HashKey][]int)}
 }
 
 //Type returns the type of the object
 func (h *Hash) Type() ObjectType { return HASH_OBJ }
 
+//find returns the position of the pair stored under key
+func (h *Hash) find(key Hashable) (int, bool) {
+	for _, i := range h.index[key.HashKey()] {
+		if key.Equals(h.pairs[i].Key) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+//Get returns the value stored under key
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	i, ok := h.find(key)
+	if !ok {
+		return nil, false
+	}
+	return h.pairs[i].Value, true
+}
+
+//Set stores value under key. a new key is added after the existing ones,
+// an existing key keeps its position
+func (h *Hash) Set(key Hashable, value Object) {
+	if h.index == nil {
+		h.index = make(map[HashKey][]int)
+	}
+	if i, ok := h.find(key); ok {
+		h.pairs[i].Value = value
+		return
+	}
+	hashKey := key.HashKey()
+	h.index[hashKey] = append(h.index[hashKey], len(h.pairs))
+	h.pairs = append(h.pairs, HashPair{Key: key, Value: value})
+}
+
+//Delete removes key from the hash and reports whether it was present.
+// only the index entries of key change, apart from an occasional compaction,
+// so deleting takes amortised constant time
+func (h *Hash) Delete(key Hashable) bool {
+	i, ok := h.find(key)
+	if !ok {
+		return false
+	}
+	hashKey := key.HashKey()
+	positions := h.index[hashKey]
+	for j, position := range positions {
+		if position == i {
+			positions = append(positions[:j], positions[j+1:]...)
+			break
+		}
+	}
+	if len(positions) == 0 {
+		delete(h.index, hashKey)
+	} else {
+		h.index[hashKey] = positions
+	}
+	h.pairs[i] = HashPair{}
+	h.deleted++
+	if h.deleted*2 >= len(h.pairs) {
+		h.compact()
+	}
+	return true
+}
+
+//compact drops deleted pairs and rebuilds the index for the new positions
+func (h *Hash) compact() {
+	pairs := make([]HashPair, 0, len(h.pairs)-h.deleted)
+	h.index = make(map[HashKey][]int, len(h.pairs)-h.deleted)
+	for _, pair := range h.pairs {
+		if pair.Key == nil {
+			continue
+		}
+		hashKey := pair.Key.(Hashable).HashKey()
+		h.index[hashKey] = append(h.index[hashKey], len(pairs))
+		pairs = append(pairs, pair)
+	}
+	h.pairs = pairs
+	h.deleted = 0
+}
+
+//Len returns the number of pairs in the hash
+func (h *Hash) Len() int { return len(h.pairs) - h.deleted }
+
+//Pairs returns the pairs of the hash in insertion order
+func (h *Hash) Pairs() []HashPair {
+	pairs := make([]HashPair, 0, h.Len())
+	for _, pair := range h.pairs {
+		if pair.Key != nil {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
 //Inspect returns a string representation of the Hash object
 func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for _, pair := range h.Pairs {
+	for _, pair := range h.Pairs() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
 	}
 	out.WriteString("{")
@@ -240,8 +565,9 @@ func (h *Hash) Inspect() string {
 
 //Class Base handler for class
 type Class struct {
-	Name string
-	Env  *Environment
+	Name    string
+	Env     *Environment
+	Parents []*Class
 }
 
 //Type returns the type of the object
@@ -250,10 +576,43 @@ func (C *Class) Type() ObjectType { return CLASS_OBJ }
 //Inspect returns a string representation of the node
 func (C *Class) Inspect() string { return "class " + C.Name }
 
+//IsSubclassOf checks whether the class is other or inherits from it
+func (C *Class) IsSubclassOf(other *Class) bool {
+	if C == other {
+		return true
+	}
+	for _, parent := range C.Parents {
+		if parent.IsSubclassOf(other) {
+			return true
+		}
+	}
+	return false
+}
+
+//InterfaceMethod a method signature required by an interface
+type InterfaceMethod struct {
+	Name  string
+	Arity int
+}
+
+//Interface a named set of method signatures a class can promise to implement
+type Interface struct {
+	Name    string
+	Methods []InterfaceMethod
+}
+
+//Type returns the type of the object
+func (I *Interface) Type() ObjectType { return INTERFACE_OBJ }
+
+//Inspect returns a string representation of the node
+func (I *Interface) Inspect() string { return "interface " + I.Name }
+
 //ClassInstance an instance of a class
+// Env holds the instance variables and encloses the store of Class
 type ClassInstance struct {
-	Name string
-	Env  *Environment
+	Name  string
+	Env   *Environment
+	Class *Class
 }
 
 //Type returns the type of the object
@@ -273,3 +632,351 @@ func (M *Module) Type() ObjectType { return MODULE_OBJ }
 
 //Inspect returns a string representation of the node
 func (M *Module) Inspect() string { return "module " + M.Name }
+
+//TypeObject runtime representation of the type of a builtin object
+type TypeObject struct {
+	Name ObjectType
+}
+
+//Type returns the type of the object
+func (t *TypeObject) Type() ObjectType { return TYPE_OBJ }
+
+//Inspect returns a string representation of the node
+func (t *TypeObject) Inspect() string { return "<type " + string(t.Name) + ">" }
+
+//Enum a named, ordered set of members
+type Enum struct {
+	Name    string
+	Members []*EnumMember
+}
+
+//Type returns the type of the object
+func (e *Enum) Type() ObjectType { return ENUM_OBJ }
+
+//Inspect returns a string representation of the node
+func (e *Enum) Inspect() string { return "enum " + e.Name }
+
+//Member returns the member of the enum with the given name
+func (e *Enum) Member(name string) (*EnumMember, bool) {
+	for _, member := range e.Members {
+		if member.Name == name {
+			return member, true
+		}
+	}
+	return nil, false
+}
+
+//EnumMember a single member of an enum. Members are singletons
+// so they can be compared by identity
+type EnumMember struct {
+	Enum    *Enum
+	Name    string
+	Ordinal int64
+}
+
+//Type returns the type of the object
+func (em *EnumMember) Type() ObjectType { return ENUMMEMBER_OBJ }
+
+//Inspect returns a string representation of the node
+func (em *EnumMember) Inspect() string { return em.Enum.Name + "." + em.Name }
+
+//HashKey function to generate a HashKey object from an enum member
+func (em *EnumMember) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(em.Inspect()))
+	return HashKey{Type: em.Type(), Value: h.Sum64()}
+}
+
+//Equals reports whether other is the same enum member
+func (em *EnumMember) Equals(other Object) bool {
+	return other == Object(em)
+}
+
+//Record a data class declared with its field names
+type Record struct {
+	Name   string
+	Fields []string
+}
+
+//Type returns the type of the object
+func (r *Record) Type() ObjectType { return RECORD_OBJ }
+
+//Inspect returns a string representation of the node
+func (r *Record) Inspect() string { return "record " + r.Name }
+
+//RecordInstance an immutable value of a record, Values are in the order of Record.Fields
+type RecordInstance struct {
+	Record *Record
+	Values []Object
+}
+
+//Type returns the type of the object
+func (ri *RecordInstance) Type() ObjectType { return RECORDINSTANCE_OBJ }
+
+//Inspect returns a string representation of the node
+// eg. Point(x=1, y=2)
+func (ri *RecordInstance) Inspect() string {
+	fields := []string{}
+	for i, name := range ri.Record.Fields {
+		fields = append(fields, name+"="+ri.Values[i].Inspect())
+	}
+	return ri.Record.Name + "(" + strings.Join(fields, ", ") + ")"
+}
+
+//Get returns the value of a field of the record
+func (ri *RecordInstance) Get(name string) (Object, bool) {
+	for i, field := range ri.Record.Fields {
+		if field == name {
+			return ri.Values[i], true
+		}
+	}
+	return nil, false
+}
+
+//HashKey function to generate a HashKey object from the record name and its fields.
+// only records accepted by AsHashKey are used as keys, so every field is Hashable
+func (ri *RecordInstance) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(ri.Record.Name))
+	for _, value := range ri.Values {
+		writeElementKey(h, value)
+	}
+	return HashKey{Type: ri.Type(), Value: h.Sum64()}
+}
+
+//Equals reports whether other is an instance of the same record with equal fields
+func (ri *RecordInstance) Equals(other Object) bool {
+	o, ok := other.(*RecordInstance)
+	if !ok || o.Record != ri.Record {
+		return false
+	}
+	for i, value := range ri.Values {
+		if !valuesEqual(value, o.Values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+//Set an unordered collection of distinct hashable values.
+// elements are stored as the keys of a Hash so they iterate in insertion order
+type Set struct {
+	elements *Hash
+}
+
+//NewSet creates an empty Set
+func NewSet() *Set {
+	return &Set{elements: NewHash()}
+}
+
+//Type returns the type of the object
+func (s *Set) Type() ObjectType { return SET_OBJ }
+
+//Inspect returns a string representation of the set. eg. {1, 2}
+func (s *Set) Inspect() string {
+	if s.Len() == 0 {
+		return "set()"
+	}
+	elements := []string{}
+	for _, element := range s.Elements() {
+		elements = append(elements, element.Inspect())
+	}
+	return "{" + strings.Join(elements, ", ") + "}"
+}
+
+//Add adds element to the set
+func (s *Set) Add(element Hashable) { s.elements.Set(element, element) }
+
+//Has reports whether element is in the set
+func (s *Set) Has(element Hashable) bool {
+	_, ok := s.elements.Get(element)
+	return ok
+}
+
+//Remove removes element from the set and reports whether it was present
+func (s *Set) Remove(element Hashable) bool { return s.elements.Delete(element) }
+
+//Len returns the number of elements in the set
+func (s *Set) Len() int { return s.elements.Len() }
+
+//Elements returns the elements of the set in insertion order
+func (s *Set) Elements() []Object {
+	pairs := s.elements.Pairs()
+	elements := make([]Object, len(pairs))
+	for i, pair := range pairs {
+		elements[i] = pair.Key
+	}
+	return elements
+}
+
+//Tuple an immutable fixed size sequence of values. eg. (1, "a")
+type Tuple struct {
+	Elements []Object
+}
+
+//Type returns the type of the object
+func (t *Tuple) Type() ObjectType { return TUPLE_OBJ }
+
+//Inspect returns a string representation of the tuple. eg. (1, 2) or (1,)
+func (t *Tuple) Inspect() string {
+	elements := []string{}
+	for _, element := range t.Elements {
+		elements = append(elements, element.Inspect())
+	}
+	if len(elements) == 1 {
+		return "(" + elements[0] + ",)"
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
+//HashKey function to generate a HashKey object from the elements of the tuple.
+// only tuples accepted by AsHashKey are used as keys, so every element is Hashable
+func (t *Tuple) HashKey() HashKey {
+	h := fnv.New64a()
+	for _, element := range t.Elements {
+		writeElementKey(h, element)
+	}
+	return HashKey{Type: t.Type(), Value: h.Sum64()}
+}
+
+//writeElementKey adds the HashKey of an element of a tuple or record to h. other
+// elements only add their type: they make the key unusable (see AsHashKey)
+func writeElementKey(h io.Writer, element Object) {
+	if hashable, ok := element.(Hashable); ok {
+		key := hashable.HashKey()
+		fmt.Fprintf(h, "|%s:%v", key.Type, key.Value)
+	} else {
+		fmt.Fprintf(h, "|%s", element.Type())
+	}
+}
+
+//Equals reports whether other is a tuple with equal elements
+func (t *Tuple) Equals(other Object) bool {
+	o, ok := other.(*Tuple)
+	if !ok || len(o.Elements) != len(t.Elements) {
+		return false
+	}
+	for i, element := range t.Elements {
+		if !valuesEqual(element, o.Elements[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+//BoundMethod a method that remembers the receiver it was looked up on
+// eg. the value of player.play
+type BoundMethod struct {
+	Name     string
+	Receiver Object
+	Method   Object // a *Function or *Builtin
+}
+
+//Type returns the type of the object
+func (bm *BoundMethod) Type() ObjectType { return BOUNDMETHOD_OBJ }
+
+//Inspect returns a string representation of the node
+func (bm *BoundMethod) Inspect() string {
+	return "<bound method " + bm.Name + " of " + bm.Receiver.Inspect() + ">"
+}
+
+//DateTime an instant in time along with the time zone it is shown in
+type DateTime struct {
+	Value time.Time
+}
+
+//Type returns the type of the object
+func (d *DateTime) Type() ObjectType { return DATETIME_OBJ }
+
+//Inspect returns the date time in RFC 3339 format. eg. 2024-03-01T12:30:00+01:00
+func (d *DateTime) Inspect() string { return d.Value.Format(time.RFC3339Nano) }
+
+//HashKey function to generate a HashKey object from the instant of the date time
+func (d *DateTime) HashKey() HashKey {
+	return HashKey{Type: d.Type(), Value: uint64(d.Value.UnixNano())}
+}
+
+//Equals reports whether other is a DateTime of the same instant, whatever its time zone
+func (d *DateTime) Equals(other Object) bool {
+	o, ok := other.(*DateTime)
+	return ok && o.Value.Equal(d.Value)
+}
+
+//Duration a span of time with nanosecond precision
+type Duration struct {
+	Value time.Duration
+}
+
+//Type returns the type of the object
+func (d *Duration) Type() ObjectType { return DURATION_OBJ }
+
+//Inspect returns the duration in Go's format. eg. 1h30m0s
+func (d *Duration) Inspect() string { return d.Value.String() }
+
+//HashKey function to generate a HashKey object from a Duration
+func (d *Duration) HashKey() HashKey {
+	return HashKey{Type: d.Type(), Value: uint64(d.Value)}
+}
+
+//Equals reports whether other is a Duration of the same length
+func (d *Duration) Equals(other Object) bool {
+	o, ok := other.(*Duration)
+	return ok && o.Value == d.Value
+}
+
+//Regex a compiled regular expression
+type Regex struct {
+	Value *regexp.Regexp
+}
+
+//Type returns the type of the object
+func (r *Regex) Type() ObjectType { return REGEX_OBJ }
+
+//Inspect returns the regex as a literal. flags show up in the pattern. eg. r/(?i)abc/
+func (r *Regex) Inspect() string { return "r/" + r.Value.String() + "/" }
+
+//Bytes an immutable sequence of bytes. eg. b"\x89PNG"
+type Bytes struct {
+	Value []byte
+}
+
+//Type returns the type of the object
+func (b *Bytes) Type() ObjectType { return BYTES_OBJ }
+
+//Inspect returns the bytes as a literal, escaping anything that is not printable ASCII
+func (b *Bytes) Inspect() string {
+	var out strings.Builder
+	out.WriteString(`b"`)
+	for _, c := range b.Value {
+		switch {
+		case c == '"' || c == '\\':
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		case c == '\n':
+			out.WriteString(`\n`)
+		case c == '\r':
+			out.WriteString(`\r`)
+		case c == '\t':
+			out.WriteString(`\t`)
+		case c < 0x20 || c > 0x7e:
+			fmt.Fprintf(&out, `\x%02x`, c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	out.WriteString(`"`)
+	return out.String()
+}
+
+//HashKey function to generate a HashKey object from the content of the bytes
+func (b *Bytes) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write(b.Value)
+	return HashKey{Type: b.Type(), Value: h.Sum64()}
+}
+
+//Equals reports whether other is a Bytes with the same content
+func (b *Bytes) Equals(other Object) bool {
+	o, ok := other.(*Bytes)
+	return ok && bytes.Equal(o.Value, b.Value)
+}
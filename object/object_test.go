@@ -0,0 +1,148 @@
+package object
+
+import (
+	"hash/fnv"
+	"math"
+	"testing"
+)
+
+//collidingKey always hashes to the same HashKey, forcing every key into one bucket
+type collidingKey struct {
+	name string
+}
+
+func (c *collidingKey) Type() ObjectType { return "COLLIDING" }
+func (c *collidingKey) Inspect() string  { return c.name }
+func (c *collidingKey) HashKey() HashKey { return HashKey{Type: c.Type(), Value: 42} }
+func (c *collidingKey) Equals(other Object) bool {
+	o, ok := other.(*collidingKey)
+	return ok && o.name == c.name
+}
+
+func TestHashCollidingKeys(t *testing.T) {
+	hash := NewHash()
+	a, b, c := &collidingKey{"a"}, &collidingKey{"b"}, &collidingKey{"c"}
+	hash.Set(a, &Integer{Value: 1})
+	hash.Set(b, &Integer{Value: 2})
+	hash.Set(c, &Integer{Value: 3})
+	hash.Set(&collidingKey{"b"}, &Integer{Value: 20})
+
+	if hash.Len() != 3 {
+		t.Fatalf("hash has wrong length. got=%d, want=3", hash.Len())
+	}
+	expected := map[string]int64{"a": 1, "b": 20, "c": 3}
+	for name, want := range expected {
+		value, ok := hash.Get(&collidingKey{name})
+		if !ok {
+			t.Fatalf("key %s not found", name)
+		}
+		if value.(*Integer).Value != want {
+			t.Errorf("key %s has wrong value. got=%d, want=%d", name, value.(*Integer).Value, want)
+		}
+	}
+
+	if !hash.Delete(&collidingKey{"a"}) {
+		t.Fatalf("Delete did not find key a")
+	}
+	if _, ok := hash.Get(a); ok {
+		t.Errorf("key a still present after Delete")
+	}
+	if value, ok := hash.Get(c); !ok || value.(*Integer).Value != 3 {
+		t.Errorf("key c lost after deleting a colliding key. got=%v", value)
+	}
+	if hash.Inspect() != "{b: 20, c: 3}" {
+		t.Errorf("hash has wrong order. got=%s", hash.Inspect())
+	}
+}
+
+func TestHashDeleteKeepsOrder(t *testing.T) {
+	hash := NewHash()
+	for i := int64(0); i < 10; i++ {
+		hash.Set(&Integer{Value: i}, &Integer{Value: i * i})
+	}
+	// odd keys first, so that deleting crosses the compaction threshold
+	for _, i := range []int64{1, 3, 5, 7, 9, 0, 8} {
+		if !hash.Delete(&Integer{Value: i}) {
+			t.Fatalf("Delete did not find key %d", i)
+		}
+		if hash.Delete(&Integer{Value: i}) {
+			t.Fatalf("Delete found key %d twice", i)
+		}
+	}
+	hash.Set(&Integer{Value: 3}, &Integer{Value: 33})
+	hash.Set(&Integer{Value: 4}, &Integer{Value: 44})
+
+	if hash.Len() != 4 {
+		t.Fatalf("hash has wrong length. got=%d, want=4", hash.Len())
+	}
+	if hash.Inspect() != "{2: 4, 4: 44, 6: 36, 3: 33}" {
+		t.Errorf("hash has wrong content. got=%s", hash.Inspect())
+	}
+	for key, want := range map[int64]int64{2: 4, 3: 33, 4: 44, 6: 36} {
+		value, ok := hash.Get(&Integer{Value: key})
+		if !ok || value.(*Integer).Value != want {
+			t.Errorf("key %d has wrong value. got=%v, want=%d", key, value, want)
+		}
+	}
+	if len(hash.Pairs()) != 4 {
+		t.Errorf("Pairs has wrong length. got=%d, want=4", len(hash.Pairs()))
+	}
+}
+
+func TestHashKeysThatCollidedAsFloats(t *testing.T) {
+	// these FNV-64a hashes differ but round to the same float64
+	first, second := "k18gbjzvgkka", "kvpq5xtw2uw"
+	if float64(fnv64a(first)) != float64(fnv64a(second)) {
+		t.Fatalf("test strings no longer collide as float64")
+	}
+	tests := []struct {
+		first  Hashable
+		second Hashable
+	}{
+		{&String{Value: first}, &String{Value: second}},
+		{&Integer{Value: 1 << 53}, &Integer{Value: 1<<53 + 1}},
+		{&Integer{Value: 1<<62 + 1}, &Integer{Value: 1<<62 + 2}},
+	}
+
+	for _, tt := range tests {
+		hash := NewHash()
+		hash.Set(tt.first, &Integer{Value: 1})
+		hash.Set(tt.second, &Integer{Value: 2})
+		if hash.Len() != 2 {
+			t.Errorf("%s and %s share a slot. got len=%d", tt.first.Inspect(), tt.second.Inspect(), hash.Len())
+			continue
+		}
+		value, _ := hash.Get(tt.first)
+		if value.(*Integer).Value != 1 {
+			t.Errorf("%s was overwritten by %s", tt.first.Inspect(), tt.second.Inspect())
+		}
+	}
+}
+
+func TestHashKeyEquality(t *testing.T) {
+	tests := []struct {
+		first    Hashable
+		second   Hashable
+		expected bool
+	}{
+		{&String{Value: "a"}, &String{Value: "a"}, true},
+		{&Integer{Value: 1}, &Float{Value: 1}, false},
+		{&Float{Value: 0}, &Float{Value: math.Copysign(0, -1)}, true},
+		{&Boolean{Value: true}, &Integer{Value: 1}, false},
+	}
+
+	for _, tt := range tests {
+		hash := NewHash()
+		hash.Set(tt.first, &Null{})
+		_, ok := hash.Get(tt.second)
+		if ok != tt.expected {
+			t.Errorf("Get(%s) after Set(%s) got=%t, want=%t", tt.second.Inspect(), tt.first.Inspect(), ok, tt.expected)
+		}
+	}
+}
+
+func fnv64a(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
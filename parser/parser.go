@@ -2,37 +2,44 @@ package parser
 
 import (
 	"fmt"
+	"math/big"
 	"monkey/ast"
 	"monkey/lexer"
 	"monkey/token"
 	"strconv"
+	"strings"
 )
 
 const (
 	_ int = iota
 	LOWEST
 	EQUALS      // ==
-	LESSGREATER // > or <
+	LESSGREATER // > or < or in
+	UNION       // |
+	INTERSECT   // &
 	SUM         // +  or -
 	PRODUCT     // *
 	PREFIX      // -X or !X
-	DOT         // .
 	CALL        //myFunction(X)
+	DOT         // myObject.property
 	INDEX       //myArray[index]
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.DOT:      DOT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
+	token.EQ:        EQUALS,
+	token.NOT_EQ:    EQUALS,
+	token.LT:        LESSGREATER,
+	token.GT:        LESSGREATER,
+	token.IN:        LESSGREATER,
+	token.PIPE:      UNION,
+	token.AMPERSAND: INTERSECT,
+	token.PLUS:      SUM,
+	token.MINUS:     SUM,
+	token.SLASH:     PRODUCT,
+	token.ASTERISK:  PRODUCT,
+	token.DOT:       DOT,
+	token.LPAREN:    CALL,
+	token.LBRACKET:  INDEX,
 }
 
 type (
@@ -197,6 +204,12 @@ func (p *Parser) parseIdentifier() ast.Expression {
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		// too large for an int64, evaluated as a BigInt
+		if lit.Big, ok = new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			return lit
+		}
+	}
 	if err != nil {
 		msg := fmt.Sprintf("Could not parsse %q as integer", p.curToken.Literal)
 		p.errors = append(p.errors, msg)
@@ -252,19 +265,54 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+//parseDotExpression : parses attribute access and returns a DotExpression Node.
+// keywords are allowed as names so that eg. re.match can be used
+func (p *Parser) parseDotExpression(left ast.Expression) ast.Expression {
+	expression := &ast.DotExpression{Token: p.curToken, Left: left}
+	if !p.peekTokenIs(token.IDENT) && token.LookupIdent(p.peekToken.Literal) == token.IDENT {
+		p.peekError(token.IDENT)
+		return nil
+	}
+	p.nextToken()
+	expression.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	return expression
+}
+
 //parseBoolean : Parse a boolean and return a Boolean Node
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
 
-//parseGroupedExpression parse grouped expression (expressions enclosed in brackets)
+//parseGroupedExpression parse grouped expression (expressions enclosed in brackets).
+// a comma inside the brackets makes a tuple instead. eg. (1, 2), (1,) or ()
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	tuple := &ast.TupleLiteral{Token: p.curToken}
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		tuple.Elements = []ast.Expression{}
+		return tuple
+	}
 	p.nextToken()
 	exp := p.parseExpression(LOWEST)
+	if !p.peekTokenIs(token.COMMA) {
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+		return exp
+	}
+	tuple.Elements = []ast.Expression{exp}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if p.peekTokenIs(token.RPAREN) {
+			break
+		}
+		p.nextToken()
+		tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
+	}
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
-	return exp
+	return tuple
 }
 
 //parseIfExpression parses and returns an IfExpression Node
@@ -346,6 +394,13 @@ func (p *Parser) parseClassLiteral() ast.Expression {
 	}
 	p.nextToken()
 	cls.Parents = parents
+	if p.peekTokenIs(token.IMPLEMENTS) {
+		p.nextToken()
+		cls.Interfaces = p.parseIdentifierList()
+		if cls.Interfaces == nil {
+			return nil
+		}
+	}
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
@@ -354,6 +409,150 @@ func (p *Parser) parseClassLiteral() ast.Expression {
 	return cls
 }
 
+//parseIdentifierList parses a non empty comma separated list of identifiers
+// eg. the interfaces after the implements keyword
+func (p *Parser) parseIdentifierList() []*ast.Identifier {
+	identifiers := []*ast.Identifier{}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	identifiers = append(identifiers, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		identifiers = append(identifiers, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	}
+	return identifiers
+}
+
+//parseEnumStatement parses an enum declaration
+// eg. enum Color { Red, Green, Blue }
+func (p *Parser) parseEnumStatement() ast.Expression {
+	enum := &ast.EnumStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	enum.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	enum.Members = []*ast.Identifier{}
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		enum.Members = append(enum.Members, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+	return enum
+}
+
+//parseRecordStatement parses a record declaration
+// eg. record Point(x, y)
+func (p *Parser) parseRecordStatement() ast.Expression {
+	record := &ast.RecordStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	record.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	record.Fields = p.parseFunctionParameters()
+	if record.Fields == nil {
+		return nil
+	}
+	return record
+}
+
+//parseMatchExpression parses a match expression made of `pattern => body` arms.
+// a body is either a single expression or a block, `_` matches anything
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	expression.Arms = []*ast.MatchArm{}
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		arm := &ast.MatchArm{}
+		if !p.curTokenIs(token.IDENT) || p.curToken.Literal != "_" {
+			arm.Pattern = p.parseExpression(LOWEST)
+		}
+		if !p.expectPeek(token.ARROW) {
+			return nil
+		}
+		arm.Token = p.curToken
+		if p.peekTokenIs(token.LBRACE) {
+			p.nextToken()
+			arm.Body = p.parseBlockStatement()
+		} else {
+			p.nextToken()
+			stmt := &ast.ExpressionStatement{Token: p.curToken, Expression: p.parseExpression(LOWEST)}
+			arm.Body = &ast.BlockStatement{Token: arm.Token, Statements: []ast.Statement{stmt}}
+		}
+		expression.Arms = append(expression.Arms, arm)
+		if p.peekTokenIs(token.COMMA) || p.peekTokenIs(token.SEMICOLON) {
+			p.nextToken()
+		}
+	}
+	p.nextToken()
+	return expression
+}
+
+//parseInterfaceStatement parses an interface declaration made of method signatures
+func (p *Parser) parseInterfaceStatement() ast.Expression {
+	iface := &ast.InterfaceStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	iface.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	iface.Methods = []*ast.InterfaceMethod{}
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.FUNCTION) {
+			return nil
+		}
+		method := &ast.InterfaceMethod{Token: p.curToken}
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		method.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if !p.expectPeek(token.LPAREN) {
+			return nil
+		}
+		method.Parameters = p.parseFunctionParameters()
+		if method.Parameters == nil {
+			return nil
+		}
+		if p.peekTokenIs(token.SEMICOLON) {
+			p.nextToken()
+		}
+		iface.Methods = append(iface.Methods, method)
+	}
+	p.nextToken()
+	return iface
+}
+
 func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	identifiers := []*ast.Identifier{}
 
@@ -404,7 +603,60 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+//parseRegexLiteral : parse a regex literal, splitting r/pattern/flags into its parts
+func (p *Parser) parseRegexLiteral() ast.Expression {
+	literal := p.curToken.Literal
+	end := strings.LastIndex(literal, "/")
+	return &ast.RegexLiteral{Token: p.curToken, Pattern: literal[2:end], Flags: literal[end+1:]}
+}
+
+//parseBytesLiteral : parse a bytes literal, decoding its escape sequences.
+// \xNN is any byte, along with \n, \r, \t, \0, \\ and \"
+func (p *Parser) parseBytesLiteral() ast.Expression {
+	literal := p.curToken.Literal
+	value := []byte{}
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '\\' {
+			value = append(value, literal[i])
+			continue
+		}
+		i++
+		if i == len(literal) {
+			p.errors = append(p.errors, fmt.Sprintf("unterminated escape in bytes literal b\"%s\"", literal))
+			return nil
+		}
+		switch escape := literal[i]; escape {
+		case 'n':
+			value = append(value, '\n')
+		case 'r':
+			value = append(value, '\r')
+		case 't':
+			value = append(value, '\t')
+		case '0':
+			value = append(value, 0)
+		case '\\', '"':
+			value = append(value, escape)
+		case 'x':
+			if i+2 < len(literal) {
+				if b, err := strconv.ParseUint(literal[i+1:i+3], 16, 8); err == nil {
+					value = append(value, byte(b))
+					i += 2
+					continue
+				}
+			}
+			p.errors = append(p.errors, fmt.Sprintf("invalid \\x escape in bytes literal b\"%s\"", literal))
+			return nil
+		default:
+			p.errors = append(p.errors, fmt.Sprintf("invalid escape \\%c in bytes literal b\"%s\"", escape, literal))
+			return nil
+		}
+	}
+	return &ast.BytesLiteral{Token: p.curToken, Value: value}
+}
+
 //parseHashLiteral : parse and return a HashLiteral object. aka maps, hashmap, etc
+// a first element without a colon makes a SetLiteral instead. eg. {1, 2}
+// {} is always an empty hash
 func (p *Parser) parseHashLiteral() ast.Expression {
 	hash := &ast.HashLiteral{Token: p.curToken}
 	hash.Pairs = make(map[ast.Expression]ast.Expression)
@@ -412,6 +664,9 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	for !p.peekTokenIs(token.RBRACE) {
 		p.nextToken()
 		key := p.parseExpression(LOWEST)
+		if len(hash.Keys) == 0 && !p.peekTokenIs(token.COLON) {
+			return p.parseSetLiteral(hash.Token, key)
+		}
 		if !p.expectPeek(token.COLON) {
 			return nil
 		}
@@ -419,6 +674,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		p.nextToken()
 		value := p.parseExpression(LOWEST)
 		hash.Pairs[key] = value
+		hash.Keys = append(hash.Keys, key)
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
 		}
@@ -427,6 +683,23 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	return hash
 }
 
+//parseSetLiteral : parse the rest of a SetLiteral whose first element is already parsed
+func (p *Parser) parseSetLiteral(tok token.Token, first ast.Expression) ast.Expression {
+	set := &ast.SetLiteral{Token: tok, Elements: []ast.Expression{first}}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if p.peekTokenIs(token.RBRACE) {
+			break
+		}
+		p.nextToken()
+		set.Elements = append(set.Elements, p.parseExpression(LOWEST))
+	}
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return set
+}
+
 //parseArrayLiteral : parse and return an ArrayLiteral node
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.curToken}
@@ -463,7 +736,28 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	if p.curTokenIs(token.RBRACKET) {
 		p.errors = append(p.errors, "Expected an expression between `[` and `]`")
 	}
+	if p.curTokenIs(token.COLON) {
+		return p.parseSliceExpression(exp.Token, left, nil)
+	}
 	exp.Index = p.parseExpression(LOWEST)
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		return p.parseSliceExpression(exp.Token, left, exp.Index)
+	}
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	return exp
+}
+
+//parseSliceExpression : parse the rest of a slice once its colon is the current token
+// eg. items[1:3], items[:3], items[1:] or items[:]
+func (p *Parser) parseSliceExpression(tok token.Token, left, start ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+	if !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.End = p.parseExpression(LOWEST)
+	}
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
@@ -474,6 +768,15 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: p.curToken}
 
+	if p.peekTokenIs(token.LPAREN) {
+		// unpacking. eg. let (a, b) = pair;
+		p.nextToken()
+		stmt.Names = p.parseIdentifierList()
+		if stmt.Names == nil || !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+		return p.parseLetValue(stmt)
+	}
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
@@ -484,7 +787,21 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 			return nil
 		}
 		stmt.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	} else {
+		for p.peekTokenIs(token.LBRACKET) {
+			p.nextToken()
+			p.nextToken()
+			stmt.Indices = append(stmt.Indices, p.parseExpression(LOWEST))
+			if !p.expectPeek(token.RBRACKET) {
+				return nil
+			}
+		}
 	}
+	return p.parseLetValue(stmt)
+}
+
+//parseLetValue : parse the `= value` part of a LetStatement
+func (p *Parser) parseLetValue(stmt *ast.LetStatement) *ast.LetStatement {
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
@@ -584,16 +901,24 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.WHILE, p.parseWhileExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.REGEX, p.parseRegexLiteral)
+	p.registerPrefix(token.BYTES, p.parseBytesLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 	p.registerPrefix(token.CLASS, p.parseClassLiteral)
+	p.registerPrefix(token.INTERFACE, p.parseInterfaceStatement)
+	p.registerPrefix(token.ENUM, p.parseEnumStatement)
+	p.registerPrefix(token.RECORD, p.parseRecordStatement)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
+	// implements is only a keyword in class headers, elsewhere it names the builtin
+	p.registerPrefix(token.IMPLEMENTS, p.parseIdentifier)
 	p.registerPrefix(token.IMPORT, p.parseImportStatement)
 
 	// Infix expressions
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 
 	//register infix parse functions
-	p.registerInfix(token.DOT, p.parseInfixExpression)
+	p.registerInfix(token.DOT, p.parseDotExpression)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
@@ -602,6 +927,9 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.IN, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 	return p
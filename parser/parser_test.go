@@ -41,6 +41,157 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestIndexAssignmentParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let h["a"] = 1;`, `let h[a] = 1;`},
+		{`let xs[i + 1] = {"b": 2, "a": 1};`, `let xs[(i + 1)] = {b:2, a:1};`},
+		{`let m[0]["a"][i] = 1;`, `let m[0][a][i] = 1;`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.LetStatement. got=%T", program.Statements[0])
+		}
+		if len(stmt.Indices) == 0 {
+			t.Fatalf("stmt.Indices is empty")
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() wrong. got=%q, want=%q", stmt.String(), tt.expected)
+		}
+	}
+}
+
+func TestSetTupleAndInParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{1, 2, 3}`, `{1, 2, 3}`},
+		{`{1, 2,}`, `{1, 2}`},
+		{`{}`, `{}`},
+		{`{"a": 1}`, `{a:1}`},
+		{`(1, 2)`, `(1, 2)`},
+		{`(1,)`, `(1,)`},
+		{`()`, `()`},
+		{`(1)`, `1`},
+		{`a | b & c`, `(a | (b & c))`},
+		{`a | b == c`, `((a | b) == c)`},
+		{`x in a | b`, `(x in (a | b))`},
+		{`let (a, b) = f();`, `let (a, b) = f();`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestRegexLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedPattern string
+		expectedFlags   string
+	}{
+		{`r/[a-z]+/`, `[a-z]+`, ""},
+		{`r/a\/b/im`, `a\/b`, "im"},
+		{`r/(\d+)-(\d+)/;`, `(\d+)-(\d+)`, ""},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		regex, ok := stmt.Expression.(*ast.RegexLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.RegexLiteral. got=%T", stmt.Expression)
+		}
+		if regex.Pattern != tt.expectedPattern || regex.Flags != tt.expectedFlags {
+			t.Errorf("wrong regex. got pattern=%q flags=%q, want pattern=%q flags=%q",
+				regex.Pattern, regex.Flags, tt.expectedPattern, tt.expectedFlags)
+		}
+	}
+}
+
+func TestBytesLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []byte
+	}{
+		{`b"abc"`, []byte("abc")},
+		{`b""`, []byte{}},
+		{`b"\x00\xff\x7F"`, []byte{0, 255, 127}},
+		{`b"\n\r\t\0\\\""`, []byte{'\n', '\r', '\t', 0, '\\', '"'}},
+		{`b"é"`, []byte("é")},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.BytesLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.BytesLiteral. got=%T", stmt.Expression)
+		}
+		if string(literal.Value) != string(tt.expected) {
+			t.Errorf("wrong bytes. got=%q, want=%q", literal.Value, tt.expected)
+		}
+	}
+
+	for _, input := range []string{`b"\q"`, `b"\x4"`, `b"\xzz"`} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parser error for %s", input)
+		}
+	}
+}
+
+func TestSliceExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`a[1:2]`, `(a[1:2])`},
+		{`a[:2]`, `(a[:2])`},
+		{`a[1:]`, `(a[1:])`},
+		{`a[:]`, `(a[:])`},
+		{`a[x + 1:-1][0]`, `((a[(x + 1):(-1)])[0])`},
+		{`[{"k": 1}[k:]]`, `[({k:1}[k:])]`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input         string
@@ -556,6 +707,156 @@ func TestClassParsing(t *testing.T) {
 
 }
 
+func TestInterfaceParsing(t *testing.T) {
+	input := `interface Shape { fn area(); fn scale(x, y); }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+	iface, ok := stmt.Expression.(*ast.InterfaceStatement)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.InterfaceStatement. got=%T", stmt.Expression)
+	}
+	testLiteralExpression(t, iface.Name, "Shape")
+	if len(iface.Methods) != 2 {
+		t.Fatalf("interface methods wrong. want 2, got=%d\n", len(iface.Methods))
+	}
+	testLiteralExpression(t, iface.Methods[0].Name, "area")
+	if len(iface.Methods[0].Parameters) != 0 {
+		t.Errorf("area parameters wrong. want 0, got=%d", len(iface.Methods[0].Parameters))
+	}
+	testLiteralExpression(t, iface.Methods[1].Name, "scale")
+	testLiteralExpression(t, iface.Methods[1].Parameters[0], "x")
+	testLiteralExpression(t, iface.Methods[1].Parameters[1], "y")
+}
+
+func TestClassImplementsParsing(t *testing.T) {
+	input := `class Square(Base) implements Shape, Printable { }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	cls, ok := stmt.Expression.(*ast.ClassStatement)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.ClassStatement. got=%T", stmt.Expression)
+	}
+	testLiteralExpression(t, cls.Parents[0], "Base")
+	if len(cls.Interfaces) != 2 {
+		t.Fatalf("class interfaces wrong. want 2, got=%d\n", len(cls.Interfaces))
+	}
+	testLiteralExpression(t, cls.Interfaces[0], "Shape")
+	testLiteralExpression(t, cls.Interfaces[1], "Printable")
+}
+
+func TestEnumAndRecordParsing(t *testing.T) {
+	input := `enum Color { Red, Green, Blue }; record Point(x, y)`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+			2, len(program.Statements))
+	}
+	enum, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.EnumStatement)
+	if !ok {
+		t.Fatalf("statement 0 is not ast.EnumStatement. got=%T", program.Statements[0])
+	}
+	testLiteralExpression(t, enum.Name, "Color")
+	members := []string{"Red", "Green", "Blue"}
+	if len(enum.Members) != len(members) {
+		t.Fatalf("enum members wrong. want %d, got=%d", len(members), len(enum.Members))
+	}
+	for i, member := range members {
+		testLiteralExpression(t, enum.Members[i], member)
+	}
+
+	record, ok := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.RecordStatement)
+	if !ok {
+		t.Fatalf("statement 1 is not ast.RecordStatement. got=%T", program.Statements[1])
+	}
+	testLiteralExpression(t, record.Name, "Point")
+	testLiteralExpression(t, record.Fields[0], "x")
+	testLiteralExpression(t, record.Fields[1], "y")
+}
+
+func TestMatchExpressionParsing(t *testing.T) {
+	input := `match (x) { 1 => 10, y => { y }; _ => 30 }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MatchExpression. got=%T", stmt.Expression)
+	}
+	testLiteralExpression(t, exp.Subject, "x")
+	if len(exp.Arms) != 3 {
+		t.Fatalf("match arms wrong. want 3, got=%d", len(exp.Arms))
+	}
+	testLiteralExpression(t, exp.Arms[0].Pattern, 1)
+	testLiteralExpression(t, exp.Arms[1].Pattern, "y")
+	if exp.Arms[2].Pattern != nil {
+		t.Errorf("wildcard arm has a pattern. got=%s", exp.Arms[2].Pattern)
+	}
+	bodies := []string{"10", "y", "30"}
+	for i, body := range bodies {
+		if exp.Arms[i].Body.String() != body {
+			t.Errorf("arm %d body wrong. want=%q, got=%q", i, body, exp.Arms[i].Body.String())
+		}
+	}
+}
+
+func TestDotExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a.b", "a.b"},
+		{"a.b.c", "a.b.c"},
+		{"a.b(1)", "a.b(1)"},
+		{"a.b.c(1, 2)", "a.b.c(1, 2)"},
+		{"getPlayer().play()", "getPlayer().play()"},
+		{"a.b[0]", "(a.b[0])"},
+		{"a[0].b", "(a[0]).b"},
+		{"-a.b", "(-a.b)"},
+		{"a.b + c.d", "(a.b + c.d)"},
+		{`"abc".upper()`, "abc.upper()"},
+		{"re.match(x)", "re.match(x)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
 func TestFunctionLiteralParsing(t *testing.T) {
 	input := `fn(x, y) { x + y; }`
 
@@ -9,23 +9,28 @@ import (
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parser"
+	"strings"
 )
 
 const PROMPT = ">> "
 
-//Start starts a repl
+//Start starts a repl. lines are read without buffering ahead of them, so scripts calling
+// input or readLine get the lines typed after theirs when in is evaluator.Stdin()
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+	reader, ok := in.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReader(in)
+	}
 	env := object.NewEnvironment()
 
 	for {
 		fmt.Printf(PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
 			return
 		}
 
-		line := scanner.Text()
+		line = strings.TrimRight(line, "\r\n")
 		l := lexer.New(line)
 		p := parser.New(l)
 
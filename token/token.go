@@ -9,22 +9,27 @@ const (
 	IDENT  = "IDENT"  // add, foobar, x, y, ...
 	INT    = "INT"    // 1234
 	STRING = "STRING" // eg. "kofi is a boy"
+	REGEX  = "REGEX"  // eg. r/[a-z]+/i
+	BYTES  = "BYTES"  // eg. b"\x00\xff"
 
 	// OPERATORS
 
-	ASSIGN   = "="
-	PLUS     = "+"
-	MINUS    = "-"
-	BANG     = "!"
-	ASTERISK = "*"
-	SLASH    = "/"
-	LT       = "<"
-	GT       = ">"
-	LTE      = "<="
-	GTE      = ">="
-	EQ       = "=="
-	NOT_EQ   = "!="
-	DOT      = "."
+	ASSIGN    = "="
+	PLUS      = "+"
+	MINUS     = "-"
+	BANG      = "!"
+	ASTERISK  = "*"
+	SLASH     = "/"
+	LT        = "<"
+	GT        = ">"
+	LTE       = "<="
+	GTE       = ">="
+	EQ        = "=="
+	NOT_EQ    = "!="
+	DOT       = "."
+	ARROW     = "=>"
+	PIPE      = "|"
+	AMPERSAND = "&"
 
 	// DELIMITERS
 
@@ -53,6 +58,13 @@ const (
 	WHILE    = "WHILE"
 	AS       = "AS"
 	NULL     = "NULL"
+
+	INTERFACE  = "INTERFACE"
+	IMPLEMENTS = "IMPLEMENTS"
+	ENUM       = "ENUM"
+	RECORD     = "RECORD"
+	MATCH      = "MATCH"
+	IN         = "IN"
 )
 
 //keywords : A map that contains a list of all keywords
@@ -69,6 +81,13 @@ var keywords = map[string]TokenType{
 	"while":  WHILE,
 	"as":     AS,
 	"null":   NULL,
+
+	"interface":  INTERFACE,
+	"implements": IMPLEMENTS,
+	"enum":       ENUM,
+	"record":     RECORD,
+	"match":      MATCH,
+	"in":         IN,
 }
 
 //LookupIdent : Checks if an identifier string is a keyword